@@ -0,0 +1,243 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	// cassandraImageName specifies Docker image name for Cassandra.
+	cassandraImageName = "cassandra:4.1"
+
+	// cassandraMaxKeyspaceNameLength is the maximum length of a keyspace name allowed by Cassandra.
+	cassandraMaxKeyspaceNameLength = 48
+)
+
+var (
+	// keyspaceNameInvalidChars matches characters which are not allowed in unquoted keyspace names.
+	keyspaceNameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+	// testKeyspaceCounter makes names of the keyspaces created by AllocateKeyspace unique.
+	testKeyspaceCounter atomic.Int64
+)
+
+// CassandraDockerInstance is a config with Cassandra connection settings.
+type CassandraDockerInstance struct {
+	// HostPort is an address of the CQL native transport reachable from the host, e.g. "localhost:9042".
+	HostPort         string
+	cassandraSession *gocql.Session
+}
+
+// Session returns a Cassandra session which isn't bound to any keyspace,
+// so statements must use keyspace-qualified table names.
+func (c *CassandraDockerInstance) Session() *gocql.Session {
+	return c.cassandraSession
+}
+
+// CassandraOption configures the Cassandra test container started by RunCassandraDockerContainer.
+type CassandraOption func(*cassandraConfig)
+
+// cassandraConfig holds Cassandra test container settings.
+type cassandraConfig struct {
+	image          string
+	startupTimeout time.Duration
+}
+
+// WithCassandraImage overrides the default Cassandra Docker image.
+// ScyllaDB images, e.g. "scylladb/scylla:5.4", are supported as well.
+func WithCassandraImage(image string) CassandraOption {
+	return func(cfg *cassandraConfig) {
+		cfg.image = image
+	}
+}
+
+// WithCassandraStartupTimeout overrides how long to wait for the CQL native transport to accept queries.
+func WithCassandraStartupTimeout(timeout time.Duration) CassandraOption {
+	return func(cfg *cassandraConfig) {
+		cfg.startupTimeout = timeout
+	}
+}
+
+// RunCassandraDockerContainer creates new single-node Cassandra test container and initializes application repositories.
+// The container is considered started once the CQL native transport accepts queries.
+// Returns cleanup function that must be called.
+func RunCassandraDockerContainer(opts ...CassandraOption) (CassandraDockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		cqlInternalPort = "9042"
+	)
+
+	cfg := cassandraConfig{
+		image:          cassandraImageName,
+		startupTimeout: defaultCassandraStartupTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	cqlPort := nat.Port(cqlInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        cfg.image,
+			ExposedPorts: []string{cqlPort.Port()},
+			Env: map[string]string{
+				// a single node doesn't need to wait for the gossip to settle:
+				"JVM_OPTS":      "-Dcassandra.skip_wait_for_gossip_to_settle=0 -Dcassandra.initial_token=0",
+				"MAX_HEAP_SIZE": "1G",
+				"HEAP_NEWSIZE":  "256M",
+			},
+			WaitingFor: forCQLReady(cqlPort, cfg.startupTimeout),
+		},
+		Started: true, // auto-start the container
+	}
+	cassandraContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return CassandraDockerInstance{}, func() {}, fmt.Errorf("cassandra container start: %w", err)
+	}
+
+	var cassandraSession *gocql.Session
+
+	// Test container clean up function:
+	terminateFn := func() {
+		if cassandraSession != nil {
+			cassandraSession.Close()
+		}
+		if err := cassandraContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("failed to terminate Cassandra test container: %v", err)
+			return
+		}
+		stdlog.Println("Cassandra test container terminated")
+	}
+
+	cassandraHostIP, err := cassandraContainer.Host(ctx)
+	if err != nil {
+		return CassandraDockerInstance{}, terminateFn, fmt.Errorf("map Cassandra host: %w", err)
+	}
+
+	cqlHostPort, err := cassandraContainer.MappedPort(ctx, cqlPort)
+	if err != nil {
+		return CassandraDockerInstance{}, terminateFn, fmt.Errorf("map Cassandra port: %w", err)
+	}
+
+	hostPort := net.JoinHostPort(cassandraHostIP, cqlHostPort.Port())
+
+	// setup Cassandra session:
+	cassandraSession, err = newCassandraSession(hostPort, "")
+	if err != nil {
+		return CassandraDockerInstance{}, terminateFn, fmt.Errorf("failed to connect to Cassandra: %w", err)
+	}
+
+	instance := CassandraDockerInstance{
+		HostPort:         hostPort,
+		cassandraSession: cassandraSession,
+	}
+	stdlog.Printf("Cassandra container started, running at: %q\n", hostPort)
+	return instance, terminateFn, nil
+}
+
+// ApplySchemaFiles executes CQL statements from all "*.cql" files in the directory,
+// applied in lexical order of file names (e.g. "001_users.cql", "002_orders.cql").
+// When the keyspace is not empty, statements are executed in it, so table names don't need to be keyspace-qualified.
+//
+// Statements in a file are split on ";", so string literals in the statements must not contain semicolons.
+func (c *CassandraDockerInstance) ApplySchemaFiles(ctx context.Context, keyspace, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.cql"))
+	if err != nil {
+		return fmt.Errorf("list Cassandra schema files in %q: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	session := c.cassandraSession
+	if keyspace != "" {
+		session, err = newCassandraSession(c.HostPort, keyspace)
+		if err != nil {
+			return fmt.Errorf("connect to Cassandra keyspace %q: %w", keyspace, err)
+		}
+		defer session.Close()
+	}
+
+	for _, file := range files {
+		schema, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read Cassandra schema file: %w", err)
+		}
+		for _, statement := range strings.Split(string(schema), ";") {
+			if strings.TrimSpace(statement) == "" {
+				continue
+			}
+			if err := session.Query(statement).WithContext(ctx).Exec(); err != nil {
+				return fmt.Errorf("apply Cassandra schema file %q: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MustApplySchemaFiles is like ApplySchemaFiles, but panics if the schema can't be applied.
+func (c *CassandraDockerInstance) MustApplySchemaFiles(keyspace, dir string) {
+	if err := c.ApplySchemaFiles(context.Background(), keyspace, dir); err != nil {
+		panic(err)
+	}
+}
+
+// AllocateKeyspace creates a keyspace isolated to the test, so that parallel tests don't collide,
+// applies the schema files from the directories to it, and returns its name along with a session bound to it.
+// The keyspace is dropped when the test finishes.
+func (c *CassandraDockerInstance) AllocateKeyspace(t *testing.T, schemaDirs ...string) (keyspace string, session *gocql.Session) {
+	t.Helper()
+	ctx := context.Background()
+	keyspace = testKeyspaceName(t)
+
+	createKeyspace := fmt.Sprintf("CREATE KEYSPACE %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1}", keyspace)
+	require.NoError(t, c.cassandraSession.Query(createKeyspace).WithContext(ctx).Exec())
+	t.Cleanup(func() {
+		if err := c.cassandraSession.Query("DROP KEYSPACE IF EXISTS " + keyspace).Exec(); err != nil {
+			t.Errorf("drop Cassandra keyspace %q: %v", keyspace, err)
+		}
+	})
+
+	for _, dir := range schemaDirs {
+		require.NoError(t, c.ApplySchemaFiles(ctx, keyspace, dir))
+	}
+
+	session, err := newCassandraSession(c.HostPort, keyspace)
+	require.NoError(t, err)
+	// cleanups run in reverse order, so the session is closed before the keyspace is dropped:
+	t.Cleanup(session.Close)
+	return keyspace, session
+}
+
+// testKeyspaceName derives a unique keyspace name from the test name, e.g. "test_3_testuserrepo_create".
+func testKeyspaceName(t *testing.T) string {
+	name := fmt.Sprintf("test_%d_%s", testKeyspaceCounter.Add(1), keyspaceNameInvalidChars.ReplaceAllString(strings.ToLower(t.Name()), "_"))
+	if len(name) > cassandraMaxKeyspaceNameLength {
+		name = name[:cassandraMaxKeyspaceNameLength]
+	}
+	return strings.TrimRight(name, "_")
+}
+
+// newCassandraSession creates a session to a single-node cluster, optionally bound to the keyspace.
+func newCassandraSession(hostPort, keyspace string) (*gocql.Session, error) {
+	cluster := gocql.NewCluster(hostPort)
+	cluster.Keyspace = keyspace
+	cluster.Consistency = gocql.One
+	cluster.Timeout = 10 * time.Second
+	cluster.ConnectTimeout = 10 * time.Second
+	// the node advertises its container address, which may be unreachable from the host:
+	cluster.DisableInitialHostLookup = true
+	return cluster.CreateSession()
+}
@@ -0,0 +1,35 @@
+package integrationtesting
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// defaultCassandraStartupTimeout specifies how long to wait for Cassandra to accept CQL queries.
+	defaultCassandraStartupTimeout = 3 * time.Minute
+	// cassandraReadyPollInterval specifies how often the CQL native transport is polled.
+	cassandraReadyPollInterval = time.Second
+)
+
+// forCQLReady creates a wait strategy for Cassandra listening on the given CQL native transport port.
+//
+// Cassandra opens the native transport port some time before it is able to serve queries,
+// so unlike waiting for the listening port, it succeeds only when a query against the system keyspace succeeds.
+func forCQLReady(port nat.Port, timeout time.Duration) *pollingStrategy {
+	return forPolling(port, timeout, cassandraReadyPollInterval, "CQL native transport", checkCQLReady)
+}
+
+// checkCQLReady connects to the CQL native transport and runs a query against the system keyspace.
+func checkCQLReady(ctx context.Context, hostPort string) error {
+	session, err := newCassandraSession(hostPort, "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var releaseVersion string
+	return session.Query("SELECT release_version FROM system.local").WithContext(ctx).Scan(&releaseVersion)
+}
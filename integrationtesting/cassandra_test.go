@@ -0,0 +1,30 @@
+package integrationtesting
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestKeyspaceName(t *testing.T) {
+	validKeyspaceName := regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$`)
+
+	t.Run("Sub-Test With Spaces/and.dots", func(t *testing.T) {
+		name := testKeyspaceName(t)
+		assert.Regexp(t, validKeyspaceName, name)
+		assert.Regexp(t, `^test_\d+_testtestkeyspacename_sub_test_with_`, name)
+		assert.LessOrEqual(t, len(name), cassandraMaxKeyspaceNameLength)
+	})
+
+	t.Run(strings.Repeat("very_long_name_", 10), func(t *testing.T) {
+		name := testKeyspaceName(t)
+		assert.Regexp(t, validKeyspaceName, name)
+		assert.LessOrEqual(t, len(name), cassandraMaxKeyspaceNameLength)
+	})
+
+	t.Run("unique", func(t *testing.T) {
+		assert.NotEqual(t, testKeyspaceName(t), testKeyspaceName(t))
+	})
+}
@@ -2,82 +2,403 @@ package integrationtesting
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
 	stdlog "log"
 	"math/rand"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 const (
 	// elasticImageName specifies Docker image name for ElasticSearch.
 	elasticImageName = "elasticsearch:7.17.4"
+	// elasticSecureImageName specifies Docker image name for ElasticSearch started with security enabled.
+	elasticSecureImageName = "elasticsearch:8.11.3"
+
+	// elasticSuperUserName is a name of the built-in ElasticSearch superuser.
+	elasticSuperUserName = "elastic"
+	// elasticCACertPath is a path to the CA certificate generated by ElasticSearch 8.x security auto-configuration.
+	elasticCACertPath = "/usr/share/elasticsearch/config/certs/http_ca.crt"
+
+	// defaultElasticHeapSize specifies default JVM heap size of the search engine.
+	defaultElasticHeapSize = "512m"
+	// elasticSnapshotsDir specifies a directory inside the container where snapshot repositories are mounted.
+	elasticSnapshotsDir = "/snapshots"
 )
 
-// ElasticDockerInstance is a config with ElasticSearch connection settings.
+// ElasticDockerInstance is a config with ElasticSearch (or OpenSearch) connection settings.
 type ElasticDockerInstance struct {
 	ConnURL string
+	// UserName is a name of the superuser; it is empty unless the container was started with WithElasticSecurity.
+	UserName string
+	// UserPass is a password of the superuser; it is empty unless the container was started with WithElasticSecurity.
+	UserPass string
+	// CACert is a PEM-encoded CA certificate of the cluster; it is empty unless the container was started with WithElasticSecurity.
+	CACert []byte
+	// TLSConfig is a client TLS config trusting the cluster CA; it is nil unless the container was started with WithElasticSecurity.
+	TLSConfig  *tls.Config
+	httpClient *http.Client
+}
+
+// HTTPClient returns an HTTP client which trusts the cluster CA.
+// Requests still need to be authenticated with UserName and UserPass.
+func (e *ElasticDockerInstance) HTTPClient() *http.Client {
+	if e.httpClient == nil {
+		return http.DefaultClient
+	}
+	return e.httpClient
+}
+
+// ElasticOption configures the search engine test container started by RunElasticsearchDockerContainer
+// or RunOpenSearchDockerContainer.
+type ElasticOption func(*elasticConfig)
+
+// elasticConfig holds ElasticSearch test container settings.
+type elasticConfig struct {
+	image          string
+	startupTimeout time.Duration
+	security       bool
+	password       string
+	heapSize       string
+	memoryLock     bool
+	settings       map[string]string
+	plugins        []string
+	// snapshotRepos maps names of snapshot repositories to host directories.
+	snapshotRepos map[string]string
+	restores      []elasticSnapshotRestore
+}
+
+// elasticSnapshotRestore is a snapshot to be restored at start-up.
+type elasticSnapshotRestore struct {
+	repository string
+	snapshot   string
+}
+
+// WithElasticImage overrides the default ElasticSearch Docker image, e.g. "elasticsearch:8.11.3".
+func WithElasticImage(image string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.image = image
+	}
+}
+
+// WithElasticStartupTimeout sets how long to wait for the cluster health to become at least yellow.
+// Default is 2 minutes.
+func WithElasticStartupTimeout(timeout time.Duration) ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.startupTimeout = timeout
+	}
+}
+
+// WithElasticSecurity starts ElasticSearch 8.x with security enabled: HTTPS with a CA generated
+// by the node and the "elastic" superuser with the given password.
+// Unless overridden with WithElasticImage, "elasticsearch:8.11.3" image is used.
+//
+// For OpenSearch it enables the security plugin with demo certificates and
+// the "admin" superuser with the given password, which must be strong.
+func WithElasticSecurity(password string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.security = true
+		cfg.password = password
+	}
+}
+
+// WithElasticHeapSize sets both initial and maximum JVM heap size, e.g. "256m" or "1g".
+// Default is "512m".
+func WithElasticHeapSize(size string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.heapSize = size
+	}
+}
+
+// WithElasticMemoryLock enables "bootstrap.memory_lock", which prevents the JVM heap from being swapped out.
+// The container is started with unlimited memlock ulimit, otherwise the node fails its bootstrap checks.
+// Memory lock is disabled by default.
+func WithElasticMemoryLock() ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.memoryLock = true
+	}
+}
+
+// WithElasticSetting sets an arbitrary node setting, as if it was specified in "elasticsearch.yml"
+// (or "opensearch.yml"), e.g. WithElasticSetting("indices.query.bool.max_clause_count", "4096").
+func WithElasticSetting(key, value string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		if cfg.settings == nil {
+			cfg.settings = map[string]string{}
+		}
+		cfg.settings[key] = value
+	}
+}
+
+// WithElasticPlugins installs the given plugins before the node starts, e.g. "analysis-icu".
+// Plugins are downloaded on every container start, so this option makes the start-up slower.
+func WithElasticPlugins(plugins ...string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.plugins = append(cfg.plugins, plugins...)
+	}
+}
+
+// WithElasticSnapshotRepository mounts the host directory into the container and registers it
+// as a shared file system snapshot repository with the given name once the cluster is up.
+// The directory must be readable by the container user with UID 1000.
+//
+// A repository used by WithElasticSnapshotRestore is mounted and registered read-only,
+// so a committed dataset is never modified by the tests. Otherwise, it is registered read-write
+// for CreateSnapshot, and the directory must be writable by the container user too.
+func WithElasticSnapshotRepository(name, hostDir string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		if cfg.snapshotRepos == nil {
+			cfg.snapshotRepos = map[string]string{}
+		}
+		cfg.snapshotRepos[name] = hostDir
+	}
+}
+
+// WithElasticSnapshotRestore restores all indices from the named snapshot at start-up,
+// so that large datasets are loaded in seconds instead of being indexed in every test run.
+// The repository must be registered with WithElasticSnapshotRepository; it becomes read-only.
+func WithElasticSnapshotRestore(repository, snapshot string) ElasticOption {
+	return func(cfg *elasticConfig) {
+		cfg.restores = append(cfg.restores, elasticSnapshotRestore{repository: repository, snapshot: snapshot})
+	}
+}
+
+// isRestoredFrom reports whether any snapshot is restored from the repository at start-up.
+func (cfg *elasticConfig) isRestoredFrom(repository string) bool {
+	for _, restore := range cfg.restores {
+		if restore.repository == repository {
+			return true
+		}
+	}
+	return false
+}
+
+// searchEngine describes differences between ElasticSearch-compatible search engines.
+type searchEngine struct {
+	// name is a human-readable name of the engine used in logs and errors.
+	name string
+	// image is a default Docker image.
+	image string
+	// secureImage is a default Docker image used when security is enabled.
+	secureImage string
+	// superUserName is a name of the built-in superuser.
+	superUserName string
+	// caCertPath is a path to the CA certificate inside the container when security is enabled.
+	caCertPath string
+	// tlsServerName is a host name the HTTP certificate is valid for when security is enabled.
+	tlsServerName string
+	// javaOptsEnv is a name of the environment variable with JVM options.
+	javaOptsEnv string
+	// pluginInstallCmd is a command which installs plugins given as arguments.
+	pluginInstallCmd string
+	// entrypoint is the original image entrypoint with its command, started after plugins are installed.
+	entrypoint string
+	// env returns engine-specific environment variables.
+	env func(cfg elasticConfig) map[string]string
+}
+
+// elasticsearchEngine describes ElasticSearch.
+var elasticsearchEngine = searchEngine{
+	name:          "ElasticSearch",
+	image:         elasticImageName,
+	secureImage:   elasticSecureImageName,
+	superUserName: elasticSuperUserName,
+	caCertPath:    elasticCACertPath,
+	// the generated HTTP certificate is always valid for "localhost",
+	// while the mapped Docker host may be an arbitrary address:
+	tlsServerName:    "localhost",
+	javaOptsEnv:      "ES_JAVA_OPTS",
+	pluginInstallCmd: "bin/elasticsearch-plugin install --batch",
+	entrypoint:       "/bin/tini -- /usr/local/bin/docker-entrypoint.sh eswrapper",
+	env: func(cfg elasticConfig) map[string]string {
+		env := map[string]string{}
+		if cfg.security {
+			// security must not be enabled explicitly, otherwise ElasticSearch skips TLS auto-configuration:
+			env["ELASTIC_PASSWORD"] = cfg.password
+		} else {
+			env["xpack.security.enabled"] = "false"
+		}
+		return env
+	},
 }
 
 // RunElasticsearchDockerContainer creates new ElasticSearch test container and initializes application repositories.
+// The container is considered started once the cluster health is at least yellow.
 // Returns cleanup function that must be called.
-func RunElasticsearchDockerContainer() (ElasticDockerInstance, func(), error) {
+func RunElasticsearchDockerContainer(opts ...ElasticOption) (ElasticDockerInstance, func(), error) {
+	return runSearchDockerContainer(elasticsearchEngine, opts...)
+}
+
+// runSearchDockerContainer creates new test container for ElasticSearch-compatible search engine.
+// Returns cleanup function that must be called.
+func runSearchDockerContainer(engine searchEngine, opts ...ElasticOption) (ElasticDockerInstance, func(), error) {
 	ctx := context.Background()
 	rand.Seed(time.Now().UnixMilli())
 	const (
 		elasticInternalPort = "9200"
 
-		elasticConnectionURLTemplate = "http://%s:%s"
+		elasticConnectionURLTemplate = "%s://%s:%s"
 	)
 
+	cfg := elasticConfig{
+		startupTimeout: defaultElasticStartupTimeout,
+		heapSize:       defaultElasticHeapSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.image == "" {
+		cfg.image = engine.image
+		if cfg.security {
+			cfg.image = engine.secureImage
+		}
+	}
+
 	elasticPort := nat.Port(elasticInternalPort + "/tcp")
 	containerRequest := testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
-			Image: elasticImageName,
+			Image: cfg.image,
 			Env: map[string]string{
-				"discovery.type":         "single-node",
-				"cluster.name":           fmt.Sprintf("testcontainer-%d", rand.Int()),
-				"ES_JAVA_OPTS":           "-Xms512m -Xmx1024m",
-				"bootstrap.memory_lock":  "true",
-				"xpack.security.enabled": "false",
+				"discovery.type":   "single-node",
+				"cluster.name":     fmt.Sprintf("testcontainer-%d", rand.Int()),
+				engine.javaOptsEnv: fmt.Sprintf("-Xms%s -Xmx%s", cfg.heapSize, cfg.heapSize),
 			},
 			ExposedPorts: []string{elasticPort.Port()},
-			WaitingFor:   wait.ForListeningPort(elasticPort),
 		},
 		Started: true, // auto-start the container
 	}
+	for key, value := range engine.env(cfg) {
+		containerRequest.Env[key] = value
+	}
+	for key, value := range cfg.settings {
+		containerRequest.Env[key] = value
+	}
+	if len(cfg.snapshotRepos) > 0 {
+		containerRequest.Env["path.repo"] = elasticSnapshotsDir
+		var mounts []testcontainers.ContainerMount
+		for name, hostDir := range cfg.snapshotRepos {
+			absHostDir, err := filepath.Abs(hostDir)
+			if err != nil {
+				return ElasticDockerInstance{}, func() {}, fmt.Errorf("resolve snapshot repository %q dir: %w", name, err)
+			}
+			mount := testcontainers.BindMount(absHostDir, testcontainers.ContainerMountTarget(elasticSnapshotsDir+"/"+name))
+			mount.ReadOnly = cfg.isRestoredFrom(name)
+			mounts = append(mounts, mount)
+		}
+		containerRequest.Mounts = testcontainers.Mounts(mounts...)
+	}
+	if cfg.memoryLock {
+		containerRequest.Env["bootstrap.memory_lock"] = "true"
+		containerRequest.HostConfigModifier = func(hostConfig *container.HostConfig) {
+			hostConfig.Ulimits = append(hostConfig.Ulimits, &units.Ulimit{Name: "memlock", Soft: -1, Hard: -1})
+		}
+	}
+	if len(cfg.plugins) > 0 {
+		quotedPlugins := make([]string, 0, len(cfg.plugins))
+		for _, plugin := range cfg.plugins {
+			quotedPlugins = append(quotedPlugins, shellQuote(plugin))
+		}
+		containerRequest.Entrypoint = []string{"/bin/sh", "-c"}
+		containerRequest.Cmd = []string{
+			fmt.Sprintf("%s %s && exec %s", engine.pluginInstallCmd, strings.Join(quotedPlugins, " "), engine.entrypoint),
+		}
+	}
+	healthStrategy := forClusterHealth(elasticPort, cfg.startupTimeout)
+	if cfg.security {
+		healthStrategy = healthStrategy.withBasicAuth(engine.superUserName, cfg.password)
+	}
+	containerRequest.WaitingFor = healthStrategy
 	elasticContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
 	if err != nil {
-		return ElasticDockerInstance{}, func() {}, fmt.Errorf("elasticSearch container start: %w", err)
+		return ElasticDockerInstance{}, func() {}, fmt.Errorf("%s container start: %w", engine.name, err)
 	}
 
 	// Test container clean-up function:
 	terminateFn := func() {
 		if err := elasticContainer.Terminate(ctx); err != nil {
-			stdlog.Printf("failed to terminate ElasticSearch test container: %+v", err)
+			stdlog.Printf("failed to terminate %s test container: %+v", engine.name, err)
 			return
 		}
-		stdlog.Println("ElasticSearch test container terminated")
+		stdlog.Printf("%s test container terminated\n", engine.name)
 	}
 
 	elasticHostIP, err := elasticContainer.Host(ctx)
 	if err != nil {
-		return ElasticDockerInstance{}, terminateFn, fmt.Errorf("map ElasticSearch host: %w", err)
+		return ElasticDockerInstance{}, terminateFn, fmt.Errorf("map %s host: %w", engine.name, err)
 	}
 
 	elasticHostPort, err := elasticContainer.MappedPort(ctx, elasticPort)
 	if err != nil {
-		return ElasticDockerInstance{}, terminateFn, fmt.Errorf("map ElasticSearch port: %w", err)
+		return ElasticDockerInstance{}, terminateFn, fmt.Errorf("map %s port: %w", engine.name, err)
 	}
 
-	elasticURL := fmt.Sprintf(elasticConnectionURLTemplate, elasticHostIP, elasticHostPort.Port())
+	scheme := "http"
+	if cfg.security {
+		scheme = "https"
+	}
+	elasticURL := fmt.Sprintf(elasticConnectionURLTemplate, scheme, elasticHostIP, elasticHostPort.Port())
 	instance := ElasticDockerInstance{
 		ConnURL: elasticURL,
 	}
 
-	stdlog.Printf("ElasticSearch container started, running at: %q\n", elasticURL)
+	if cfg.security {
+		caCert, err := copyFileFromContainer(ctx, elasticContainer, engine.caCertPath)
+		if err != nil {
+			return ElasticDockerInstance{}, terminateFn, fmt.Errorf("copy %s CA certificate: %w", engine.name, err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return ElasticDockerInstance{}, terminateFn, fmt.Errorf("parse %s CA certificate", engine.name)
+		}
+		tlsConfig := &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: engine.tlsServerName,
+			MinVersion: tls.VersionTLS12,
+		}
+
+		instance.UserName = engine.superUserName
+		instance.UserPass = cfg.password
+		instance.CACert = caCert
+		instance.TLSConfig = tlsConfig
+		instance.httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	}
+
+	for name := range cfg.snapshotRepos {
+		if err := instance.RegisterSnapshotRepository(ctx, name, elasticSnapshotsDir+"/"+name, cfg.isRestoredFrom(name)); err != nil {
+			return ElasticDockerInstance{}, terminateFn, err
+		}
+	}
+	for _, restore := range cfg.restores {
+		if err := instance.RestoreSnapshot(ctx, restore.repository, restore.snapshot); err != nil {
+			return ElasticDockerInstance{}, terminateFn, err
+		}
+	}
+
+	stdlog.Printf("%s container started, running at: %q\n", engine.name, elasticURL)
 	return instance, terminateFn, nil
 }
+
+// copyFileFromContainer reads the whole file from the container.
+func copyFileFromContainer(ctx context.Context, container testcontainers.Container, path string) ([]byte, error) {
+	reader, err := container.CopyFileFromContainer(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// shellQuote quotes the string to be used as a single argument in a POSIX shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
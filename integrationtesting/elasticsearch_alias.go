@@ -0,0 +1,213 @@
+package integrationtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// elasticTaskPollInterval specifies how often running tasks are polled.
+	elasticTaskPollInterval = 200 * time.Millisecond
+	// elasticAliasSearchInterval specifies a pause between searches made by MonitorAliasSearches.
+	elasticAliasSearchInterval = 10 * time.Millisecond
+	// maxReportedAliasSearchFailures limits the number of failures reported by MonitorAliasSearches.
+	maxReportedAliasSearchFailures = 10
+)
+
+// AliasTargets returns sorted names of the indices the alias points to.
+// It returns an empty list if the alias doesn't exist.
+func (e *ElasticDockerInstance) AliasTargets(ctx context.Context, alias string) ([]string, error) {
+	var resp map[string]struct {
+		Aliases map[string]any `json:"aliases"`
+	}
+	err := e.doJSON(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, &resp)
+	var apiErr *httpAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get alias %q: %w", alias, err)
+	}
+
+	indices := make([]string, 0, len(resp))
+	for index := range resp {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// WaitForTask waits until the task, e.g. started by "_reindex?wait_for_completion=false", completes.
+// It returns an error if the task failed, or if it reported failures of individual documents.
+func (e *ElasticDockerInstance) WaitForTask(ctx context.Context, taskID string) error {
+	for {
+		var resp struct {
+			Completed bool           `json:"completed"`
+			Error     map[string]any `json:"error"`
+			Response  struct {
+				Failures []any `json:"failures"`
+			} `json:"response"`
+		}
+		if err := e.doJSON(ctx, http.MethodGet, "/_tasks/"+url.PathEscape(taskID), nil, &resp); err != nil {
+			return fmt.Errorf("get task %q: %w", taskID, err)
+		}
+		if resp.Completed {
+			if resp.Error != nil {
+				return fmt.Errorf("task %q failed: %v", taskID, resp.Error)
+			}
+			if len(resp.Response.Failures) > 0 {
+				return fmt.Errorf("task %q completed with %d failures: %v", taskID, len(resp.Response.Failures), resp.Response.Failures)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for task %q: %w", taskID, ctx.Err())
+		case <-time.After(elasticTaskPollInterval):
+		}
+	}
+}
+
+// WaitForReindexTasks waits until there are no running reindex tasks in the cluster.
+func (e *ElasticDockerInstance) WaitForReindexTasks(ctx context.Context) error {
+	for {
+		var resp struct {
+			Nodes map[string]struct {
+				Tasks map[string]any `json:"tasks"`
+			} `json:"nodes"`
+		}
+		if err := e.doJSON(ctx, http.MethodGet, "/_tasks?actions=*reindex", nil, &resp); err != nil {
+			return fmt.Errorf("list reindex tasks: %w", err)
+		}
+		running := 0
+		for _, node := range resp.Nodes {
+			running += len(node.Tasks)
+		}
+		if running == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for %d reindex tasks: %w", running, ctx.Err())
+		case <-time.After(elasticTaskPollInterval):
+		}
+	}
+}
+
+// MonitorAliasSearches continuously searches through the alias for the expected documents
+// until the returned stop function is called, e.g. while a reindex and an alias swap run concurrently.
+//
+// The stop function reports a test failure if any search returned an error,
+// or didn't return all the expected documents.
+func (e *ElasticDockerInstance) MonitorAliasSearches(t *testing.T, alias string, expectedIDs ...string) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		wg       sync.WaitGroup
+		searches int
+		failures []string
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			err := e.searchExpectedIDs(ctx, alias, expectedIDs)
+			if ctx.Err() != nil {
+				return // the search was interrupted by stop
+			}
+			searches++
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("search #%d: %v", searches, err))
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(elasticAliasSearchInterval):
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		t.Helper()
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+
+			t.Logf("%d searches through alias %q made, %d failed", searches, alias, len(failures))
+			if len(failures) > maxReportedAliasSearchFailures {
+				failures = append(failures[:maxReportedAliasSearchFailures], "...")
+			}
+			for _, failure := range failures {
+				t.Errorf("alias %q: %s", alias, failure)
+			}
+		})
+	}
+}
+
+// searchExpectedIDs searches through the alias and returns an error if any of the expected documents is missing
+// or any of the shards failed to respond.
+func (e *ElasticDockerInstance) searchExpectedIDs(ctx context.Context, alias string, expectedIDs []string) error {
+	query := map[string]any{
+		// while the alias points to both old and new indices, every document is returned twice:
+		"size":    2 * len(expectedIDs),
+		"_source": false,
+		"query":   map[string]any{"ids": map[string]any{"values": expectedIDs}},
+	}
+	var resp struct {
+		Shards struct {
+			Total    int `json:"total"`
+			Failed   int `json:"failed"`
+			Failures []struct {
+				Index  string `json:"index"`
+				Shard  int    `json:"shard"`
+				Reason struct {
+					Type   string `json:"type"`
+					Reason string `json:"reason"`
+				} `json:"reason"`
+			} `json:"failures"`
+		} `json:"_shards"`
+		Hits struct {
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := e.doJSON(ctx, http.MethodPost, "/"+url.PathEscape(alias)+"/_search", query, &resp); err != nil {
+		return err
+	}
+	// a search with failed shards still succeeds, but returns partial results:
+	if resp.Shards.Failed > 0 {
+		reasons := make([]string, 0, len(resp.Shards.Failures))
+		for _, failure := range resp.Shards.Failures {
+			reasons = append(reasons, fmt.Sprintf("index %q shard %d: %s: %s",
+				failure.Index, failure.Shard, failure.Reason.Type, failure.Reason.Reason))
+		}
+		return fmt.Errorf("%d of %d shards failed: %v", resp.Shards.Failed, resp.Shards.Total, reasons)
+	}
+
+	found := make(map[string]struct{}, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		found[hit.ID] = struct{}{}
+	}
+	var missing []string
+	for _, id := range expectedIDs {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d of %d documents are missing: %v", len(missing), len(expectedIDs), missing)
+	}
+	return nil
+}
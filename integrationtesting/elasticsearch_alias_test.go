@@ -0,0 +1,56 @@
+package integrationtesting
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchExpectedIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		wantErr string
+	}{
+		{
+			name: "all documents found",
+			resp: `{"_shards": {"total": 2, "successful": 2, "failed": 0},
+"hits": {"hits": [{"_id": "1"}, {"_id": "2"}, {"_id": "1"}]}}`,
+		},
+		{
+			name:    "missing documents",
+			resp:    `{"_shards": {"total": 1, "successful": 1, "failed": 0}, "hits": {"hits": [{"_id": "2"}]}}`,
+			wantErr: "1 of 2 documents are missing: [1]",
+		},
+		{
+			name: "failed shards",
+			resp: `{"_shards": {"total": 2, "successful": 1, "failed": 1, "failures": [
+  {"shard": 0, "index": "products-v2", "reason": {"type": "no_shard_available_action_exception", "reason": "no shard available"}}
+]}, "hits": {"hits": [{"_id": "1"}, {"_id": "2"}]}}`,
+			wantErr: `1 of 2 shards failed: [index "products-v2" shard 0: no_shard_available_action_exception: no shard available]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/products/_search", r.URL.Path)
+				w.Header().Set("Content-Type", contentTypeJSON)
+				_, _ = io.WriteString(w, tt.resp)
+			}))
+			t.Cleanup(server.Close)
+			elastic := &ElasticDockerInstance{ConnURL: server.URL}
+
+			err := elastic.searchExpectedIDs(context.Background(), "products", []string{"1", "2"})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package integrationtesting
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ElasticAnalyzeRequest describes a text to be analyzed by the "_analyze" API.
+type ElasticAnalyzeRequest struct {
+	// Index is an optional index whose custom analyzers and field mappings are used.
+	Index string `json:"-"`
+	// Analyzer is a name of a built-in analyzer or a custom analyzer defined in Index.
+	Analyzer string `json:"analyzer,omitempty"`
+	// Field is a name of a field in Index whose analyzer is used; it is an alternative to Analyzer.
+	Field string `json:"field,omitempty"`
+	// Text is a text to be analyzed.
+	Text string `json:"text"`
+}
+
+// ElasticToken is a single token produced by an analyzer.
+type ElasticToken struct {
+	Token       string `json:"token"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	// Type is a token type, e.g. "<ALPHANUM>" or "word".
+	Type     string `json:"type"`
+	Position int    `json:"position"`
+}
+
+// Analyze runs the text through the analyzer and returns the produced tokens.
+func (e *ElasticDockerInstance) Analyze(ctx context.Context, req ElasticAnalyzeRequest) ([]ElasticToken, error) {
+	path := "/_analyze"
+	if req.Index != "" {
+		path = "/" + url.PathEscape(req.Index) + path
+	}
+
+	var resp struct {
+		Tokens []ElasticToken `json:"tokens"`
+	}
+	if err := e.doJSON(ctx, http.MethodPost, path, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tokens, nil
+}
+
+// AssertAnalyzedTokens asserts that the analyzer produces exactly the expected terms, in order.
+func (e *ElasticDockerInstance) AssertAnalyzedTokens(t *testing.T, req ElasticAnalyzeRequest, expected ...string) bool {
+	t.Helper()
+	tokens, err := e.Analyze(context.Background(), req)
+	require.NoError(t, err)
+
+	if len(expected) == 0 {
+		return assert.Emptyf(t, tokens, "tokens produced for %q", req.Text)
+	}
+	actual := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		actual = append(actual, token.Token)
+	}
+	return assert.Equalf(t, expected, actual, "tokens produced for %q", req.Text)
+}
+
+// AssertAnalyzedTokenDetails asserts that the analyzer produces exactly the expected tokens,
+// including their positions and offsets. Token types are compared only when set in expected tokens.
+func (e *ElasticDockerInstance) AssertAnalyzedTokenDetails(t *testing.T, req ElasticAnalyzeRequest, expected []ElasticToken) bool {
+	t.Helper()
+	actual, err := e.Analyze(context.Background(), req)
+	require.NoError(t, err)
+
+	if len(expected) == 0 {
+		return assert.Emptyf(t, actual, "tokens produced for %q", req.Text)
+	}
+	if len(actual) == len(expected) {
+		for i := range expected {
+			if expected[i].Type == "" {
+				actual[i].Type = ""
+			}
+		}
+	}
+	return assert.Equalf(t, expected, actual, "tokens produced for %q", req.Text)
+}
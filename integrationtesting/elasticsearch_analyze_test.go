@@ -0,0 +1,100 @@
+package integrationtesting
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// analyzeRequest is a request received by the fake "_analyze" API.
+type analyzeRequest struct {
+	Path string
+	Body string
+}
+
+// fakeAnalyzeAPI records the requests received by the fake "_analyze" API, so that they are checked by the test.
+type fakeAnalyzeAPI struct {
+	mu       sync.Mutex
+	requests []analyzeRequest
+}
+
+// received returns a copy of the requests received so far.
+func (f *fakeAnalyzeAPI) received() []analyzeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]analyzeRequest(nil), f.requests...)
+}
+
+// newFakeAnalyzeInstance returns an instance connected to a fake "_analyze" API, which responds with the tokens.
+func newFakeAnalyzeInstance(t *testing.T, tokens []ElasticToken) (*ElasticDockerInstance, *fakeAnalyzeAPI) {
+	t.Helper()
+	api := &fakeAnalyzeAPI{}
+	// the API returns an empty array rather than null when no tokens are produced:
+	if tokens == nil {
+		tokens = []ElasticToken{}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		api.mu.Lock()
+		api.requests = append(api.requests, analyzeRequest{Path: r.URL.Path, Body: string(body)})
+		api.mu.Unlock()
+
+		w.Header().Set("Content-Type", contentTypeJSON)
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"tokens": tokens}))
+	}))
+	t.Cleanup(server.Close)
+	return &ElasticDockerInstance{ConnURL: server.URL}, api
+}
+
+// assertAnalyzeRequest asserts that the fake "_analyze" API received exactly one request with the path and JSON body.
+func assertAnalyzeRequest(t *testing.T, api *fakeAnalyzeAPI, path, body string) {
+	t.Helper()
+	requests := api.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, path, requests[0].Path)
+	assert.JSONEq(t, body, requests[0].Body)
+}
+
+func TestAssertAnalyzedTokens(t *testing.T) {
+	elastic, api := newFakeAnalyzeInstance(t, []ElasticToken{
+		{Token: "red", StartOffset: 0, EndOffset: 3, Type: "<ALPHANUM>", Position: 0},
+		{Token: "shoe", StartOffset: 4, EndOffset: 9, Type: "<ALPHANUM>", Position: 1},
+	})
+
+	elastic.AssertAnalyzedTokens(t, ElasticAnalyzeRequest{Analyzer: "english", Text: "Red shoes"}, "red", "shoe")
+	assertAnalyzeRequest(t, api, "/_analyze", `{"analyzer": "english", "text": "Red shoes"}`)
+}
+
+func TestAssertAnalyzedTokensWithIndexField(t *testing.T) {
+	elastic, api := newFakeAnalyzeInstance(t, []ElasticToken{
+		{Token: "red", StartOffset: 0, EndOffset: 3, Type: "<ALPHANUM>", Position: 0},
+	})
+
+	elastic.AssertAnalyzedTokens(t, ElasticAnalyzeRequest{Index: "products", Field: "title", Text: "Red"}, "red")
+	assertAnalyzeRequest(t, api, "/products/_analyze", `{"field": "title", "text": "Red"}`)
+}
+
+func TestAssertAnalyzedTokensEmpty(t *testing.T) {
+	elastic, _ := newFakeAnalyzeInstance(t, nil)
+
+	elastic.AssertAnalyzedTokens(t, ElasticAnalyzeRequest{Analyzer: "english", Text: "the a an"})
+	elastic.AssertAnalyzedTokenDetails(t, ElasticAnalyzeRequest{Analyzer: "english", Text: "the a an"}, nil)
+}
+
+func TestAssertAnalyzedTokenDetailsIgnoresUnsetType(t *testing.T) {
+	elastic, api := newFakeAnalyzeInstance(t, []ElasticToken{
+		{Token: "red", StartOffset: 0, EndOffset: 3, Type: "<ALPHANUM>", Position: 0},
+	})
+
+	elastic.AssertAnalyzedTokenDetails(t, ElasticAnalyzeRequest{Analyzer: "standard", Text: "red"}, []ElasticToken{
+		{Token: "red", StartOffset: 0, EndOffset: 3, Position: 0},
+	})
+	assertAnalyzeRequest(t, api, "/_analyze", `{"analyzer": "standard", "text": "red"}`)
+}
@@ -0,0 +1,44 @@
+package integrationtesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// doRequest sends a request with a raw body to the search cluster and returns the response body.
+// Responses with non-2xx status codes are returned as *httpAPIError.
+func (e *ElasticDockerInstance) doRequest(ctx context.Context, method, path string, body []byte, contentType string) ([]byte, error) {
+	var auth *url.Userinfo
+	if e.UserName != "" {
+		auth = url.UserPassword(e.UserName, e.UserPass)
+	}
+	return doHTTPAPIRequest(ctx, e.HTTPClient(), e.ConnURL, auth, method, path, body, contentType)
+}
+
+// doJSON sends a request with a JSON-encoded body to the search cluster and decodes the JSON response into out.
+// Body is not sent when in is nil; response is not decoded when out is nil.
+// Raw JSON can be passed as json.RawMessage.
+func (e *ElasticDockerInstance) doJSON(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("%s %s: encode request: %w", method, path, err)
+		}
+	}
+
+	respBody, err := e.doRequest(ctx, method, path, body, contentTypeJSON)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package integrationtesting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ElasticDocument is a single document to be indexed by LoadDocuments.
+type ElasticDocument struct {
+	// ID is an optional document ID; it is generated by the cluster when empty.
+	ID string
+	// Source is a document body; it is encoded as JSON.
+	Source any
+}
+
+// elasticBulkResponse is a response of the "_bulk" API.
+type elasticBulkResponse struct {
+	Errors bool `json:"errors"`
+	// Items contains a single entry per action, keyed by the action name, e.g. "index" or "create".
+	Items []map[string]elasticBulkItem `json:"items"`
+}
+
+// elasticBulkItem is a result of a single "_bulk" action.
+type elasticBulkItem struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// LoadBulkFile sends the NDJSON file in the "_bulk" API format to the cluster,
+// and refreshes all indices the documents were written to, so that they are immediately searchable.
+// Every failed bulk item is reported as a test failure.
+func (e *ElasticDockerInstance) LoadBulkFile(t *testing.T, path string) {
+	t.Helper()
+	body, err := os.ReadFile(path)
+	require.NoError(t, err)
+	e.loadBulk(t, "", body)
+}
+
+// LoadDocuments indexes the documents into the given index,
+// and refreshes the index, so that the documents are immediately searchable.
+// Every document that fails to be indexed is reported as a test failure.
+func (e *ElasticDockerInstance) LoadDocuments(t *testing.T, index string, docs ...ElasticDocument) {
+	t.Helper()
+	if len(docs) == 0 {
+		return
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]any{}
+		if doc.ID != "" {
+			action["_id"] = doc.ID
+		}
+		require.NoError(t, encoder.Encode(map[string]any{"index": action}))
+		require.NoError(t, encoder.Encode(doc.Source))
+	}
+	e.loadBulk(t, index, body.Bytes())
+}
+
+// Refresh refreshes the given indices, making all operations performed on them visible to search.
+func (e *ElasticDockerInstance) Refresh(ctx context.Context, indices ...string) error {
+	path := "/_refresh"
+	if len(indices) > 0 {
+		escaped := make([]string, 0, len(indices))
+		for _, index := range indices {
+			escaped = append(escaped, url.PathEscape(index))
+		}
+		path = "/" + strings.Join(escaped, ",") + path
+	}
+	return e.doJSON(ctx, http.MethodPost, path, nil, nil)
+}
+
+// loadBulk sends the NDJSON body to the "_bulk" API, reports failed items and refreshes affected indices.
+// When index is not empty, it is used for actions which don't specify the index.
+func (e *ElasticDockerInstance) loadBulk(t *testing.T, index string, body []byte) {
+	t.Helper()
+	ctx := context.Background()
+
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		body = append(body, '\n') // the "_bulk" API requires a trailing newline
+	}
+	path := "/_bulk"
+	if index != "" {
+		path = "/" + url.PathEscape(index) + path
+	}
+	respBody, err := e.doRequest(ctx, http.MethodPost, path, body, contentTypeNDJSON)
+	require.NoError(t, err)
+
+	var resp elasticBulkResponse
+	require.NoError(t, json.Unmarshal(respBody, &resp))
+
+	indices := map[string]struct{}{}
+	for i, item := range resp.Items {
+		for action, result := range item {
+			indices[result.Index] = struct{}{}
+			if result.Error != nil {
+				t.Errorf("bulk item #%d (%s %s/%s) failed with status %d: %s", i, action, result.Index, result.ID, result.Status, result.Error)
+			}
+		}
+	}
+	if len(indices) == 0 {
+		return
+	}
+
+	refreshIndices := make([]string, 0, len(indices))
+	for name := range indices {
+		refreshIndices = append(refreshIndices, name)
+	}
+	sort.Strings(refreshIndices)
+	require.NoErrorf(t, e.Refresh(ctx, refreshIndices...), "refresh indices %v", refreshIndices)
+}
@@ -0,0 +1,95 @@
+package integrationtesting
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// defaultElasticStartupTimeout specifies how long to wait for a search cluster to become healthy.
+	defaultElasticStartupTimeout = 2 * time.Minute
+	// elasticHealthPollInterval specifies how often the cluster health is polled.
+	elasticHealthPollInterval = 500 * time.Millisecond
+)
+
+// clusterHealthStrategy waits until "GET /_cluster/health?wait_for_status=yellow" returns 200.
+// If the cluster doesn't become healthy in time, the last health response is included in the error.
+type clusterHealthStrategy struct {
+	*pollingStrategy
+	client   *http.Client
+	useTLS   bool
+	userName string
+	userPass string
+}
+
+// forClusterHealth creates a wait strategy for a search cluster listening on the given port.
+func forClusterHealth(port nat.Port, timeout time.Duration) *clusterHealthStrategy {
+	s := &clusterHealthStrategy{
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // readiness check only
+			},
+		},
+	}
+	s.pollingStrategy = forPolling(port, timeout, elasticHealthPollInterval, "cluster health", s.checkHealth)
+	return s
+}
+
+// withBasicAuth makes the strategy use HTTPS with the given credentials.
+// The server certificate is not verified, since the CA is only known once the cluster is up.
+func (s *clusterHealthStrategy) withBasicAuth(userName, userPass string) *clusterHealthStrategy {
+	s.useTLS = true
+	s.userName = userName
+	s.userPass = userPass
+	return s
+}
+
+// checkHealth returns nil once the cluster listening at the "host:port" address is at least yellow.
+func (s *clusterHealthStrategy) checkHealth(ctx context.Context, hostPort string) error {
+	healthURL := url.URL{
+		Scheme:   "http",
+		Host:     hostPort,
+		Path:     "/_cluster/health",
+		RawQuery: "wait_for_status=yellow&timeout=1s",
+	}
+	if s.useTLS {
+		healthURL.Scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if s.userName != "" {
+		req.SetBasicAuth(s.userName, s.userPass)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+var (
+	_ wait.Strategy        = &clusterHealthStrategy{}
+	_ wait.StrategyTimeout = &clusterHealthStrategy{}
+)
@@ -0,0 +1,101 @@
+package integrationtesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// elasticDefinitionKind describes how JSON files from a subdirectory of index definitions are applied.
+type elasticDefinitionKind struct {
+	// dir is a name of the subdirectory.
+	dir string
+	// method is an HTTP method used to apply each file.
+	method string
+	// pathFn builds a request path from the definition name, i.e. the file name without extension.
+	pathFn func(name string) string
+}
+
+// elasticDefinitionKinds lists the supported subdirectories in the order they are applied:
+// component templates go first, since index templates are composed of them, and
+// index templates go before indices, so that they are applied to the explicitly created indices.
+var elasticDefinitionKinds = []elasticDefinitionKind{
+	{dir: "component_templates", method: http.MethodPut, pathFn: func(name string) string { return "/_component_template/" + url.PathEscape(name) }},
+	{dir: "index_templates", method: http.MethodPut, pathFn: func(name string) string { return "/_index_template/" + url.PathEscape(name) }},
+	{dir: "indices", method: http.MethodPut, pathFn: func(name string) string { return "/" + url.PathEscape(name) }},
+	{dir: "aliases", method: http.MethodPost, pathFn: func(string) string { return "/_aliases" }},
+}
+
+// ApplyIndexDefinitions applies index definitions from JSON files in the following subdirectories of dir:
+//   - "component_templates/<name>.json" - a body of "PUT /_component_template/<name>";
+//   - "index_templates/<name>.json" - a body of "PUT /_index_template/<name>";
+//   - "indices/<name>.json" - a body of "PUT /<name>" with index settings, mappings and aliases;
+//   - "aliases/*.json" - a body of "POST /_aliases" with alias actions.
+//
+// Missing subdirectories are skipped. Files within a subdirectory are applied in lexical order of their names.
+func (e *ElasticDockerInstance) ApplyIndexDefinitions(ctx context.Context, dir string) error {
+	for _, kind := range elasticDefinitionKinds {
+		files, err := filepath.Glob(filepath.Join(dir, kind.dir, "*.json"))
+		if err != nil {
+			return fmt.Errorf("list index definitions in %q: %w", filepath.Join(dir, kind.dir), err)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			body, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("read index definition: %w", err)
+			}
+			if !json.Valid(body) {
+				return fmt.Errorf("index definition %q is not a valid JSON", file)
+			}
+
+			name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			if _, err := e.doRequest(ctx, kind.method, kind.pathFn(name), body, contentTypeJSON); err != nil {
+				return fmt.Errorf("apply index definition %q: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MustApplyIndexDefinitions is like ApplyIndexDefinitions, but panics if definitions can't be applied.
+func (e *ElasticDockerInstance) MustApplyIndexDefinitions(dir string) {
+	if err := e.ApplyIndexDefinitions(context.Background(), dir); err != nil {
+		panic(err)
+	}
+}
+
+// DeleteAllIndices deletes all user indices in the cluster, i.e. indices whose names don't start with a dot.
+// Can be used after the tests to clean up all the user's data.
+func (e *ElasticDockerInstance) DeleteAllIndices(ctx context.Context) error {
+	var indices []struct {
+		Index string `json:"index"`
+	}
+	if err := e.doJSON(ctx, http.MethodGet, "/_cat/indices?format=json&h=index&expand_wildcards=open,closed", nil, &indices); err != nil {
+		return fmt.Errorf("list indices: %w", err)
+	}
+
+	for _, index := range indices {
+		if strings.HasPrefix(index.Index, ".") {
+			continue // system and hidden indices
+		}
+		if err := e.doJSON(ctx, http.MethodDelete, "/"+url.PathEscape(index.Index), nil, nil); err != nil {
+			return fmt.Errorf("delete index %q: %w", index.Index, err)
+		}
+	}
+	return nil
+}
+
+// MustDeleteAllIndices is like DeleteAllIndices, but panics if indices can't be deleted.
+func (e *ElasticDockerInstance) MustDeleteAllIndices() {
+	if err := e.DeleteAllIndices(context.Background()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,121 @@
+package integrationtesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ElasticSimulatedDocument is a result of running a single sample document through an ingest pipeline.
+type ElasticSimulatedDocument struct {
+	// Source is a transformed document, as produced by the last processor which didn't fail.
+	Source map[string]any
+	// Processors contains results of every processor the document went through, in order.
+	Processors []ElasticProcessorResult
+}
+
+// Errors returns results of the processors which failed, including failures ignored by the pipeline.
+func (d ElasticSimulatedDocument) Errors() []ElasticProcessorResult {
+	var failed []ElasticProcessorResult
+	for _, processor := range d.Processors {
+		if processor.Error != nil {
+			failed = append(failed, processor)
+		}
+	}
+	return failed
+}
+
+// ElasticProcessorResult is a result of a single ingest processor.
+type ElasticProcessorResult struct {
+	// ProcessorType is a type of the processor, e.g. "set" or "grok".
+	ProcessorType string `json:"processor_type"`
+	// Tag is an optional processor tag from the pipeline definition.
+	Tag string `json:"tag,omitempty"`
+	// Status is one of "success", "skipped", "error" or "error_ignored".
+	Status string `json:"status"`
+	// Error is a processor failure; it is nil when the processor succeeded.
+	Error *ElasticProcessorError `json:"error,omitempty"`
+	// Doc is a document after the processor was executed.
+	Doc *struct {
+		Source map[string]any `json:"_source"`
+	} `json:"doc,omitempty"`
+}
+
+// ElasticProcessorError describes a failure of an ingest processor.
+type ElasticProcessorError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// Error implements error.
+func (e *ElasticProcessorError) Error() string {
+	return e.Type + ": " + e.Reason
+}
+
+// PutIngestPipelines installs ingest pipelines from all "<pipeline-id>.json" files in the directory.
+// Each file contains a body of "PUT /_ingest/pipeline/<pipeline-id>".
+func (e *ElasticDockerInstance) PutIngestPipelines(ctx context.Context, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list ingest pipelines in %q: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read ingest pipeline: %w", err)
+		}
+		if !json.Valid(body) {
+			return fmt.Errorf("ingest pipeline %q is not a valid JSON", file)
+		}
+
+		pipelineID := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if _, err := e.doRequest(ctx, http.MethodPut, "/_ingest/pipeline/"+url.PathEscape(pipelineID), body, contentTypeJSON); err != nil {
+			return fmt.Errorf("put ingest pipeline %q: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// SimulateIngestPipeline runs the sample documents through the installed ingest pipeline
+// without indexing them, and returns transformed documents with per-processor results.
+func (e *ElasticDockerInstance) SimulateIngestPipeline(ctx context.Context, pipelineID string, docs ...any) ([]ElasticSimulatedDocument, error) {
+	type sampleDoc struct {
+		Source any `json:"_source"`
+	}
+	req := struct {
+		Docs []sampleDoc `json:"docs"`
+	}{}
+	for _, doc := range docs {
+		req.Docs = append(req.Docs, sampleDoc{Source: doc})
+	}
+
+	var resp struct {
+		Docs []struct {
+			ProcessorResults []ElasticProcessorResult `json:"processor_results"`
+		} `json:"docs"`
+	}
+	path := "/_ingest/pipeline/" + url.PathEscape(pipelineID) + "/_simulate?verbose=true"
+	if err := e.doJSON(ctx, http.MethodPost, path, req, &resp); err != nil {
+		return nil, fmt.Errorf("simulate ingest pipeline %q: %w", pipelineID, err)
+	}
+
+	simulated := make([]ElasticSimulatedDocument, 0, len(resp.Docs))
+	for _, doc := range resp.Docs {
+		result := ElasticSimulatedDocument{Processors: doc.ProcessorResults}
+		for _, processor := range doc.ProcessorResults {
+			if processor.Doc != nil {
+				result.Source = processor.Doc.Source
+			}
+		}
+		simulated = append(simulated, result)
+	}
+	return simulated, nil
+}
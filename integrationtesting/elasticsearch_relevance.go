@@ -0,0 +1,159 @@
+package integrationtesting
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ElasticRelevanceQuery is a single named search request in a relevance queries file.
+//
+// The queries file is a JSON array of queries, for example:
+//
+//	[
+//	  {"name": "exact title", "index": "products", "body": {"query": {"match": {"title": "red shoes"}}}},
+//	  {"name": "fuzzy title", "index": "products", "body": {"query": {"match": {"title": {"query": "red shoos", "fuzziness": "AUTO"}}}}}
+//	]
+type ElasticRelevanceQuery struct {
+	// Name uniquely identifies the query in the golden file; it is also used as a subtest name.
+	Name string `json:"name"`
+	// Index is an index, alias or comma-separated list of indices to search.
+	Index string `json:"index"`
+	// Body is a body of the "_search" request.
+	Body json.RawMessage `json:"body"`
+}
+
+// ElasticRankedHit is a single search hit in a golden file.
+type ElasticRankedHit struct {
+	ID    string   `json:"id"`
+	Score *float64 `json:"score,omitempty"`
+}
+
+// ElasticRelevanceOptions configures relevance golden tests.
+type ElasticRelevanceOptions struct {
+	// CompareScores makes hit scores to be compared with golden ones, not only hit IDs and their order.
+	CompareScores bool
+	// ScoreTolerance is a maximum allowed absolute difference between actual and golden scores.
+	ScoreTolerance float64
+	// Update makes the golden file to be overwritten with actual results instead of comparing against them,
+	// e.g. to refresh it after an intended change. It is usually wired to a flag of the test package:
+	//
+	//	var update = flag.Bool("update", false, "update golden files")
+	//	...
+	//	elastic.AssertRelevance(t, queriesFile, goldenFile, integrationtesting.ElasticRelevanceOptions{Update: *update})
+	Update bool
+}
+
+// AssertRelevance runs every query from the queries file as a subtest, and compares
+// the ranked IDs of returned documents (and optionally their scores) with the golden file,
+// which is a JSON object mapping query names to their expected hits.
+//
+// When opts.Update is set, the golden file is overwritten with actual results instead,
+// unless any of the queries failed.
+func (e *ElasticDockerInstance) AssertRelevance(t *testing.T, queriesFile, goldenFile string, opts ElasticRelevanceOptions) {
+	t.Helper()
+	queriesData, err := os.ReadFile(queriesFile)
+	require.NoError(t, err)
+	var queries []ElasticRelevanceQuery
+	require.NoErrorf(t, json.Unmarshal(queriesData, &queries), "parse relevance queries %q", queriesFile)
+	names := make(map[string]struct{}, len(queries))
+	for _, query := range queries {
+		_, duplicate := names[query.Name]
+		require.Falsef(t, duplicate, "duplicate query name %q in relevance queries %q", query.Name, queriesFile)
+		names[query.Name] = struct{}{}
+	}
+
+	golden := map[string][]ElasticRankedHit{}
+	if !opts.Update {
+		goldenData, err := os.ReadFile(goldenFile)
+		require.NoErrorf(t, err, "read golden file, run tests in update mode to create it")
+		require.NoErrorf(t, json.Unmarshal(goldenData, &golden), "parse golden file %q", goldenFile)
+	}
+
+	actual := make(map[string][]ElasticRankedHit, len(queries))
+	for _, query := range queries {
+		query := query
+		t.Run(query.Name, func(t *testing.T) {
+			hits, err := e.searchRankedHits(context.Background(), query)
+			require.NoError(t, err)
+			actual[query.Name] = hits
+
+			if opts.Update {
+				return
+			}
+			expected, ok := golden[query.Name]
+			if !assert.Truef(t, ok, "query %q is missing in golden file %q, run tests in update mode", query.Name, goldenFile) {
+				return
+			}
+			assertRankedHits(t, expected, hits, opts)
+		})
+	}
+
+	if opts.Update {
+		// results of failed queries are missing, so the golden file would lose them:
+		if t.Failed() {
+			t.Errorf("golden file %q is not updated, since some of the queries failed", goldenFile)
+			return
+		}
+		data, err := json.MarshalIndent(actual, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(goldenFile, append(data, '\n'), 0o644))
+		t.Logf("golden file %q updated", goldenFile)
+	}
+}
+
+// searchRankedHits runs the query and returns hits in the order they were ranked.
+func (e *ElasticDockerInstance) searchRankedHits(ctx context.Context, query ElasticRelevanceQuery) ([]ElasticRankedHit, error) {
+	var resp struct {
+		Hits struct {
+			Hits []struct {
+				ID    string   `json:"_id"`
+				Score *float64 `json:"_score"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := e.doJSON(ctx, http.MethodPost, "/"+url.PathEscape(query.Index)+"/_search", query.Body, &resp); err != nil {
+		return nil, err
+	}
+
+	hits := make([]ElasticRankedHit, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		hits = append(hits, ElasticRankedHit{ID: hit.ID, Score: hit.Score})
+	}
+	return hits, nil
+}
+
+// assertRankedHits compares ranked hits; it accepts assert.TestingT, so that failures can be verified in tests.
+func assertRankedHits(t assert.TestingT, expected, actual []ElasticRankedHit, opts ElasticRelevanceOptions) {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	expectedIDs := make([]string, 0, len(expected))
+	for _, hit := range expected {
+		expectedIDs = append(expectedIDs, hit.ID)
+	}
+	actualIDs := make([]string, 0, len(actual))
+	for _, hit := range actual {
+		actualIDs = append(actualIDs, hit.ID)
+	}
+	if !assert.Equal(t, expectedIDs, actualIDs, "ranked document IDs") || !opts.CompareScores {
+		return
+	}
+
+	for i := range expected {
+		if expected[i].Score == nil || actual[i].Score == nil {
+			assert.Equalf(t, expected[i].Score, actual[i].Score, "score of document %q", expected[i].ID)
+			continue
+		}
+		diff := math.Abs(*expected[i].Score - *actual[i].Score)
+		assert.LessOrEqualf(t, diff, opts.ScoreTolerance, "score of document %q: expected %v, actual %v",
+			expected[i].ID, *expected[i].Score, *actual[i].Score)
+	}
+}
@@ -0,0 +1,147 @@
+package integrationtesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT records assertion failures instead of failing the test.
+type recordingT struct {
+	errors []string
+}
+
+// Errorf implements assert.TestingT.
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func scoredHit(id string, score float64) ElasticRankedHit {
+	return ElasticRankedHit{ID: id, Score: &score}
+}
+
+func TestAssertRankedHits(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []ElasticRankedHit
+		actual   []ElasticRankedHit
+		opts     ElasticRelevanceOptions
+		wantFail bool
+	}{
+		{
+			name:     "same ranking",
+			expected: []ElasticRankedHit{{ID: "1"}, {ID: "2"}},
+			actual:   []ElasticRankedHit{scoredHit("1", 2.5), scoredHit("2", 1.5)},
+		},
+		{
+			name:     "no hits",
+			expected: []ElasticRankedHit{},
+			actual:   []ElasticRankedHit{},
+		},
+		{
+			name:     "other order",
+			expected: []ElasticRankedHit{{ID: "1"}, {ID: "2"}},
+			actual:   []ElasticRankedHit{{ID: "2"}, {ID: "1"}},
+			wantFail: true,
+		},
+		{
+			name:     "missing hit",
+			expected: []ElasticRankedHit{{ID: "1"}, {ID: "2"}},
+			actual:   []ElasticRankedHit{{ID: "1"}},
+			wantFail: true,
+		},
+		{
+			name:     "scores are ignored by default",
+			expected: []ElasticRankedHit{scoredHit("1", 2.5)},
+			actual:   []ElasticRankedHit{scoredHit("1", 9.0)},
+		},
+		{
+			name:     "scores within tolerance",
+			expected: []ElasticRankedHit{scoredHit("1", 2.5), scoredHit("2", 1.5)},
+			actual:   []ElasticRankedHit{scoredHit("1", 2.51), scoredHit("2", 1.49)},
+			opts:     ElasticRelevanceOptions{CompareScores: true, ScoreTolerance: 0.02},
+		},
+		{
+			name:     "score out of tolerance",
+			expected: []ElasticRankedHit{scoredHit("1", 2.5)},
+			actual:   []ElasticRankedHit{scoredHit("1", 2.6)},
+			opts:     ElasticRelevanceOptions{CompareScores: true, ScoreTolerance: 0.02},
+			wantFail: true,
+		},
+		{
+			name:     "missing score",
+			expected: []ElasticRankedHit{scoredHit("1", 2.5)},
+			actual:   []ElasticRankedHit{{ID: "1"}},
+			opts:     ElasticRelevanceOptions{CompareScores: true},
+			wantFail: true,
+		},
+		{
+			name:     "both scores missing",
+			expected: []ElasticRankedHit{{ID: "1"}},
+			actual:   []ElasticRankedHit{{ID: "1"}},
+			opts:     ElasticRelevanceOptions{CompareScores: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingT{}
+			assertRankedHits(recorder, tt.expected, tt.actual, tt.opts)
+			if tt.wantFail {
+				assert.NotEmpty(t, recorder.errors)
+			} else {
+				assert.Empty(t, recorder.errors)
+			}
+		})
+	}
+}
+
+// newFakeSearchInstance returns an instance connected to a fake "_search" API, which responds with the hits of the index.
+func newFakeSearchInstance(t *testing.T, hitsByIndex map[string][]ElasticRankedHit) *ElasticDockerInstance {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		index := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_search")
+
+		var hits []map[string]any
+		for _, hit := range hitsByIndex[index] {
+			hits = append(hits, map[string]any{"_id": hit.ID, "_score": hit.Score})
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"hits": map[string]any{"hits": hits}}))
+	}))
+	t.Cleanup(server.Close)
+	return &ElasticDockerInstance{ConnURL: server.URL}
+}
+
+func TestAssertRelevanceUpdateAndCompare(t *testing.T) {
+	dir := t.TempDir()
+	queriesFile := filepath.Join(dir, "queries.json")
+	goldenFile := filepath.Join(dir, "golden.json")
+	require.NoError(t, os.WriteFile(queriesFile, []byte(`[
+  {"name": "products", "index": "products", "body": {"query": {"match_all": {}}}},
+  {"name": "orders", "index": "orders", "body": {"query": {"match_all": {}}}}
+]`), 0o644))
+
+	elastic := newFakeSearchInstance(t, map[string][]ElasticRankedHit{
+		"products": {scoredHit("p1", 2), scoredHit("p2", 1)},
+		"orders":   {scoredHit("o1", 1)},
+	})
+
+	elastic.AssertRelevance(t, queriesFile, goldenFile, ElasticRelevanceOptions{Update: true})
+
+	goldenData, err := os.ReadFile(goldenFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "products": [{"id": "p1", "score": 2}, {"id": "p2", "score": 1}],
+  "orders": [{"id": "o1", "score": 1}]
+}`, string(goldenData))
+
+	elastic.AssertRelevance(t, queriesFile, goldenFile, ElasticRelevanceOptions{CompareScores: true})
+}
@@ -0,0 +1,50 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RegisterSnapshotRepository registers a shared file system snapshot repository at the location inside the container.
+// The location must be listed in "path.repo" node setting, see WithElasticSnapshotRepository.
+// A read-only repository can only be used to restore snapshots, not to create them.
+func (e *ElasticDockerInstance) RegisterSnapshotRepository(ctx context.Context, name, location string, readOnly bool) error {
+	repository := map[string]any{
+		"type":     "fs",
+		"settings": map[string]any{"location": location, "readonly": readOnly},
+	}
+	if err := e.doJSON(ctx, http.MethodPut, "/_snapshot/"+url.PathEscape(name), repository, nil); err != nil {
+		return fmt.Errorf("register snapshot repository %q: %w", name, err)
+	}
+	return nil
+}
+
+// CreateSnapshot creates a snapshot of all user indices in the repository and waits for it to complete.
+// It can be used to build a dataset once and commit the repository directory for later restores.
+func (e *ElasticDockerInstance) CreateSnapshot(ctx context.Context, repository, snapshot string) error {
+	body := map[string]any{
+		"indices":              "*,-.*",
+		"include_global_state": false,
+	}
+	path := "/_snapshot/" + url.PathEscape(repository) + "/" + url.PathEscape(snapshot) + "?wait_for_completion=true"
+	if err := e.doJSON(ctx, http.MethodPut, path, body, nil); err != nil {
+		return fmt.Errorf("create snapshot %q in repository %q: %w", snapshot, repository, err)
+	}
+	return nil
+}
+
+// RestoreSnapshot restores all user indices from the snapshot and waits for the restore to complete.
+// Indices with the same names must not exist in the cluster.
+func (e *ElasticDockerInstance) RestoreSnapshot(ctx context.Context, repository, snapshot string) error {
+	body := map[string]any{
+		"indices":              "*,-.*",
+		"include_global_state": false,
+	}
+	path := "/_snapshot/" + url.PathEscape(repository) + "/" + url.PathEscape(snapshot) + "/_restore?wait_for_completion=true"
+	if err := e.doJSON(ctx, http.MethodPost, path, body, nil); err != nil {
+		return fmt.Errorf("restore snapshot %q from repository %q: %w", snapshot, repository, err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package integrationtesting
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain", s: "analysis-icu", want: `'analysis-icu'`},
+		{name: "empty", s: "", want: `''`},
+		{name: "URL", s: "https://example.com/plugin.zip?a=1&b=2", want: `'https://example.com/plugin.zip?a=1&b=2'`},
+		{name: "single quotes", s: "it's", want: `'it'\''s'`},
+		{name: "shell metacharacters", s: "$(rm -rf /); `id` \"x\"", want: `'$(rm -rf /); ` + "`id`" + ` "x"'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shellQuote(tt.s))
+		})
+	}
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell available")
+	}
+
+	for _, s := range []string{"", "analysis-icu", "it's", "a b\tc\nd", "$(id) `id` $HOME \\ \"'\"", "''"} {
+		out, err := exec.Command(sh, "-c", "printf %s "+shellQuote(s)).Output()
+		require.NoError(t, err)
+		assert.Equal(t, s, string(out))
+	}
+}
@@ -0,0 +1,70 @@
+package integrationtesting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// httpAPIError is returned when an HTTP API of a test container responds with a non-successful status code.
+type httpAPIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+// Error implements error.
+func (e *httpAPIError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// doHTTPAPIRequest sends a request with a raw body to the HTTP API at the base URL and returns the response body.
+// The request is sent with basic auth when auth is not nil, and with http.DefaultClient when client is nil.
+// Responses with non-2xx status codes are returned as *httpAPIError.
+func doHTTPAPIRequest(ctx context.Context, client *http.Client, baseURL string, auth *url.Userinfo,
+	method, path string, body []byte, contentType string,
+) ([]byte, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(baseURL, "/")+path, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("create request %s %s: %w", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if auth != nil {
+		userPass, _ := auth.Password()
+		req.SetBasicAuth(auth.Username(), userPass)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: read response: %w", method, path, err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &httpAPIError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+	return respBody, nil
+}
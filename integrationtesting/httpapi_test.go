@@ -0,0 +1,52 @@
+package integrationtesting
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoHTTPAPIRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		userName, userPass, ok := r.BasicAuth()
+		switch {
+		case r.URL.Path == "/missing":
+			http.Error(w, "not found", http.StatusNotFound)
+		case ok:
+			_, _ = io.WriteString(w, r.Method+" "+r.URL.Path+" "+r.Header.Get("Content-Type")+" "+string(body)+" "+userName+":"+userPass)
+		default:
+			_, _ = io.WriteString(w, r.Method+" "+r.URL.Path+" "+r.Header.Get("Content-Type")+" "+string(body))
+		}
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	t.Run("basic auth and body", func(t *testing.T) {
+		resp, err := doHTTPAPIRequest(ctx, nil, server.URL+"/", url.UserPassword("user", "pass"),
+			http.MethodPost, "/api/items", []byte(`{"a":1}`), contentTypeJSON)
+		require.NoError(t, err)
+		assert.Equal(t, `POST /api/items application/json {"a":1} user:pass`, string(resp))
+	})
+
+	t.Run("no auth and no body", func(t *testing.T) {
+		resp, err := doHTTPAPIRequest(ctx, server.Client(), server.URL, nil, http.MethodGet, "/api/items", nil, contentTypeJSON)
+		require.NoError(t, err)
+		assert.Equal(t, "GET /api/items  ", string(resp))
+	})
+
+	t.Run("unexpected status code", func(t *testing.T) {
+		_, err := doHTTPAPIRequest(ctx, nil, server.URL, nil, http.MethodGet, "/missing", nil, contentTypeJSON)
+		var apiErr *httpAPIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, httpAPIError{Method: http.MethodGet, Path: "/missing", StatusCode: http.StatusNotFound, Body: "not found"}, *apiErr)
+		assert.EqualError(t, err, "GET /missing: unexpected status code 404: not found")
+	})
+}
@@ -0,0 +1,362 @@
+package integrationtesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// DockerHostGatewayName is a host name which resolves to the Docker host from inside containers
+// configured with AddDockerHostGateway.
+const DockerHostGatewayName = "host.docker.internal"
+
+// AddDockerHostGateway makes the Docker host reachable from the container as DockerHostGatewayName,
+// so that the container can call an HTTPStubServer. It is meant to be used as ContainerRequest.HostConfigModifier.
+func AddDockerHostGateway(hostConfig *container.HostConfig) {
+	hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, DockerHostGatewayName+":host-gateway")
+}
+
+// HTTPStubFault is a network-level fault injected instead of a response.
+type HTTPStubFault int
+
+const (
+	// HTTPStubNoFault sends the configured response.
+	HTTPStubNoFault HTTPStubFault = iota
+	// HTTPStubFaultCloseConnection closes the connection without sending a response.
+	HTTPStubFaultCloseConnection
+	// HTTPStubFaultMalformedResponse sends garbage instead of an HTTP response and closes the connection.
+	HTTPStubFaultMalformedResponse
+)
+
+// HTTPStubMatcher declares which requests match. Empty fields match any request.
+type HTTPStubMatcher struct {
+	// Method is an HTTP method, e.g. "POST".
+	Method string
+	// Path is a URL path pattern, as used by path.Match, e.g. "/v1/users/*".
+	Path string
+	// Query are query parameters which must be present with the given values; other parameters are ignored.
+	Query map[string]string
+	// Headers are headers which must be present with the given values; other headers are ignored.
+	Headers map[string]string
+	// BodyContains is a substring of the request body.
+	BodyContains string
+	// BodyJSON is a JSON document which must be semantically equal to the request body.
+	BodyJSON string
+}
+
+// HTTPStubResponse declares a canned response.
+type HTTPStubResponse struct {
+	// Status is an HTTP status code; it defaults to 200.
+	Status int
+	// Headers are response headers.
+	Headers map[string]string
+	// Body is a response body.
+	Body string
+	// Delay is a latency added before the response is sent.
+	Delay time.Duration
+	// Fault, when set, is injected instead of sending the response.
+	Fault HTTPStubFault
+}
+
+// HTTPStub is a declared expectation: requests matching Request are answered with Response.
+type HTTPStub struct {
+	Request  HTTPStubMatcher
+	Response HTTPStubResponse
+	// Times limits how many requests the stub answers; it is unlimited when zero.
+	Times int
+}
+
+// HTTPStubRequest is a request received by HTTPStubServer.
+type HTTPStubRequest struct {
+	Method string
+	// Path is a URL path.
+	Path   string
+	Query  map[string][]string
+	Header http.Header
+	Body   []byte
+	// Matched reports whether the request matched any stub.
+	Matched bool
+}
+
+// HTTPStubServer is an in-process HTTP server, which stands in for third-party HTTP APIs in tests.
+// Requests are answered with canned responses of the declared stubs and recorded for verification.
+// Requests which match no stub are answered with 404 Not Found.
+type HTTPStubServer struct {
+	// URL is a base URL of the server reachable from the host, e.g. "http://127.0.0.1:40123".
+	URL string
+	// ContainerURL is a base URL of the server reachable from containers configured with AddDockerHostGateway,
+	// e.g. "http://host.docker.internal:40123". With Docker Engine on Linux, it is only reachable when the server
+	// listens on the Docker bridge gateway, see WithHTTPStubListenAddress.
+	ContainerURL string
+
+	server   *http.Server
+	mu       sync.Mutex
+	stubs    []*httpStubState
+	requests []HTTPStubRequest
+}
+
+// httpStubState is a declared stub along with the number of requests it answered.
+type httpStubState struct {
+	HTTPStub
+	served int
+}
+
+// HTTPStubOption configures the HTTP stub server started by RunHTTPStubServer.
+type HTTPStubOption func(*httpStubConfig)
+
+// httpStubConfig holds HTTP stub server settings.
+type httpStubConfig struct {
+	listenAddress string
+}
+
+// WithHTTPStubListenAddress overrides the default "127.0.0.1:0" listen address.
+// With Docker Engine on Linux, DockerHostGatewayName resolves to the Docker bridge gateway rather than to the loopback
+// interface, so the server must listen on the gateway address, e.g. "172.17.0.1:0", to be reachable from containers.
+func WithHTTPStubListenAddress(address string) HTTPStubOption {
+	return func(cfg *httpStubConfig) {
+		cfg.listenAddress = address
+	}
+}
+
+// RunHTTPStubServer starts a new HTTP stub server listening on a random port of the loopback interface,
+// so recorded requests and canned responses are not exposed to the network.
+// Returns cleanup function that must be called.
+func RunHTTPStubServer(opts ...HTTPStubOption) (*HTTPStubServer, func(), error) {
+	cfg := httpStubConfig{
+		listenAddress: "127.0.0.1:0",
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	listener, err := net.Listen("tcp", cfg.listenAddress)
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("HTTP stub server listen: %w", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	host := addr.IP.String()
+	if addr.IP.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	port := strconv.Itoa(addr.Port)
+
+	stubServer := &HTTPStubServer{
+		URL:          "http://" + net.JoinHostPort(host, port),
+		ContainerURL: "http://" + net.JoinHostPort(DockerHostGatewayName, port),
+	}
+	stubServer.server = &http.Server{
+		Handler:           stubServer,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := stubServer.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			stdlog.Printf("HTTP stub server failed: %v", err)
+		}
+	}()
+
+	// HTTP stub server clean up function:
+	closeFn := func() {
+		if err := stubServer.server.Close(); err != nil {
+			stdlog.Printf("failed to close HTTP stub server: %v", err)
+			return
+		}
+		stdlog.Println("HTTP stub server closed")
+	}
+
+	stdlog.Printf("HTTP stub server started, running at: %q\n", stubServer.URL)
+	return stubServer, closeFn, nil
+}
+
+// Stub declares the stubs. When several stubs match a request, the most recently declared one answers it,
+// so tests can override default stubs.
+func (s *HTTPStubServer) Stub(stubs ...HTTPStub) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stub := range stubs {
+		s.stubs = append(s.stubs, &httpStubState{HTTPStub: stub})
+	}
+}
+
+// Reset removes all declared stubs, along with the number of requests they answered, and forgets the received requests,
+// so that the next test starts with a server answering every request with 404 Not Found.
+func (s *HTTPStubServer) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs = nil
+	s.requests = nil
+}
+
+// Requests returns a copy of all requests received so far.
+func (s *HTTPStubServer) Requests() []HTTPStubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]HTTPStubRequest(nil), s.requests...)
+}
+
+// ServeHTTP records the request and answers it with the matching stub.
+func (s *HTTPStubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	request := HTTPStubRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	s.mu.Lock()
+	stub := s.findStub(request)
+	request.Matched = stub != nil
+	s.requests = append(s.requests, request)
+	var response HTTPStubResponse
+	if stub != nil {
+		stub.served++
+		response = stub.Response
+	}
+	s.mu.Unlock()
+
+	if stub == nil {
+		http.Error(w, fmt.Sprintf("no HTTP stub matches request %s %s", r.Method, r.URL.RequestURI()), http.StatusNotFound)
+		return
+	}
+	writeStubResponse(w, r, response)
+}
+
+// findStub returns the most recently declared stub which matches the request and isn't exhausted, if any.
+func (s *HTTPStubServer) findStub(request HTTPStubRequest) *httpStubState {
+	for i := len(s.stubs) - 1; i >= 0; i-- {
+		stub := s.stubs[i]
+		if stub.Times > 0 && stub.served >= stub.Times {
+			continue
+		}
+		if stub.Request.Matches(request) {
+			return stub
+		}
+	}
+	return nil
+}
+
+// writeStubResponse sends the response after its delay, or injects its fault.
+func writeStubResponse(w http.ResponseWriter, r *http.Request, response HTTPStubResponse) {
+	if response.Delay > 0 {
+		select {
+		case <-time.After(response.Delay):
+		case <-r.Context().Done():
+			return // the client gave up
+		}
+	}
+
+	if response.Fault != HTTPStubNoFault {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "HTTP stub fault injection is not supported", http.StatusInternalServerError)
+			return
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("hijack connection: %v", err), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		if response.Fault == HTTPStubFaultMalformedResponse {
+			_, _ = conn.Write([]byte("malformed response\r\n\r\n"))
+		}
+		return
+	}
+
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, response.Body)
+}
+
+// Matches reports whether the request matches all the declared fields.
+func (m HTTPStubMatcher) Matches(request HTTPStubRequest) bool {
+	if m.Method != "" && !strings.EqualFold(m.Method, request.Method) {
+		return false
+	}
+	if m.Path != "" {
+		if ok, err := path.Match(m.Path, request.Path); err != nil || !ok {
+			return false
+		}
+	}
+	for name, value := range m.Query {
+		if !containsString(request.Query[name], value) {
+			return false
+		}
+	}
+	for name, value := range m.Headers {
+		if !containsString(request.Header.Values(name), value) {
+			return false
+		}
+	}
+	if m.BodyContains != "" && !bytes.Contains(request.Body, []byte(m.BodyContains)) {
+		return false
+	}
+	if m.BodyJSON != "" {
+		var expected, actual any
+		if json.Unmarshal([]byte(m.BodyJSON), &expected) != nil || json.Unmarshal(request.Body, &actual) != nil {
+			return false
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the matcher for assertion failures.
+func (m HTTPStubMatcher) String() string {
+	method, path := m.Method, m.Path
+	if method == "" {
+		method = "*"
+	}
+	if path == "" {
+		path = "*"
+	}
+	description := method + " " + path
+	if len(m.Query) > 0 {
+		description += fmt.Sprintf(" query=%v", m.Query)
+	}
+	if len(m.Headers) > 0 {
+		description += fmt.Sprintf(" headers=%v", m.Headers)
+	}
+	if m.BodyContains != "" {
+		description += fmt.Sprintf(" body contains %q", m.BodyContains)
+	}
+	if m.BodyJSON != "" {
+		description += fmt.Sprintf(" body JSON %s", m.BodyJSON)
+	}
+	return description
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package integrationtesting
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runTestHTTPStubServer(t *testing.T) *HTTPStubServer {
+	t.Helper()
+	stubServer, closeFn, err := RunHTTPStubServer()
+	require.NoError(t, err)
+	t.Cleanup(closeFn)
+	return stubServer
+}
+
+func doStubRequest(t *testing.T, method, url, body string, header http.Header) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody)
+}
+
+func TestHTTPStubMatcherMatches(t *testing.T) {
+	request := HTTPStubRequest{
+		Method: http.MethodPost,
+		Path:   "/v1/users/42",
+		Query:  map[string][]string{"expand": {"orders", "address"}},
+		Header: http.Header{"Authorization": {"Bearer token"}},
+		Body:   []byte(`{"name": "John", "tags": ["a", "b"]}`),
+	}
+
+	tests := []struct {
+		name    string
+		matcher HTTPStubMatcher
+		want    bool
+	}{
+		{name: "empty matcher", matcher: HTTPStubMatcher{}, want: true},
+		{name: "method is case-insensitive", matcher: HTTPStubMatcher{Method: "post"}, want: true},
+		{name: "other method", matcher: HTTPStubMatcher{Method: http.MethodGet}, want: false},
+		{name: "exact path", matcher: HTTPStubMatcher{Path: "/v1/users/42"}, want: true},
+		{name: "path glob", matcher: HTTPStubMatcher{Path: "/v1/users/*"}, want: true},
+		{name: "path glob doesn't cross segments", matcher: HTTPStubMatcher{Path: "/v1/*"}, want: false},
+		{name: "malformed path glob", matcher: HTTPStubMatcher{Path: "/v1/users/["}, want: false},
+		{name: "one of query values", matcher: HTTPStubMatcher{Query: map[string]string{"expand": "address"}}, want: true},
+		{name: "other query value", matcher: HTTPStubMatcher{Query: map[string]string{"expand": "payments"}}, want: false},
+		{name: "missing query parameter", matcher: HTTPStubMatcher{Query: map[string]string{"page": "1"}}, want: false},
+		{name: "header name is canonicalized", matcher: HTTPStubMatcher{Headers: map[string]string{"authorization": "Bearer token"}}, want: true},
+		{name: "other header value", matcher: HTTPStubMatcher{Headers: map[string]string{"Authorization": "Bearer other"}}, want: false},
+		{name: "body contains", matcher: HTTPStubMatcher{BodyContains: `"John"`}, want: true},
+		{name: "body doesn't contain", matcher: HTTPStubMatcher{BodyContains: `"Jane"`}, want: false},
+		{name: "semantically equal JSON body", matcher: HTTPStubMatcher{BodyJSON: `{"tags":["a","b"],"name":"John"}`}, want: true},
+		{name: "JSON body with other array order", matcher: HTTPStubMatcher{BodyJSON: `{"tags":["b","a"],"name":"John"}`}, want: false},
+		{name: "JSON body with missing field", matcher: HTTPStubMatcher{BodyJSON: `{"name":"John"}`}, want: false},
+		{name: "malformed JSON body matcher", matcher: HTTPStubMatcher{BodyJSON: `{`}, want: false},
+		{
+			name:    "all fields",
+			matcher: HTTPStubMatcher{Method: http.MethodPost, Path: "/v1/users/*", Query: map[string]string{"expand": "orders"}, BodyContains: "John"},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.matcher.Matches(request))
+		})
+	}
+}
+
+func TestHTTPStubMatcherMatchesNonJSONBody(t *testing.T) {
+	matcher := HTTPStubMatcher{BodyJSON: `{}`}
+	assert.False(t, matcher.Matches(HTTPStubRequest{Body: []byte("not JSON")}))
+}
+
+func TestHTTPStubServerCannedResponse(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{
+		Request: HTTPStubMatcher{Method: http.MethodGet, Path: "/v1/users/*"},
+		Response: HTTPStubResponse{
+			Status:  http.StatusAccepted,
+			Headers: map[string]string{"Content-Type": contentTypeJSON},
+			Body:    `{"id": 42}`,
+		},
+	})
+
+	status, body := doStubRequest(t, http.MethodGet, stubServer.URL+"/v1/users/42", "", nil)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.JSONEq(t, `{"id": 42}`, body)
+
+	status, _ = doStubRequest(t, http.MethodGet, stubServer.URL+"/v1/orders/42", "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	requests := stubServer.Requests()
+	require.Len(t, requests, 2)
+	assert.True(t, requests[0].Matched)
+	assert.False(t, requests[1].Matched)
+	assert.Equal(t, "/v1/orders/42", requests[1].Path)
+}
+
+func TestHTTPStubServerDefaultStatus(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/ping"}, Response: HTTPStubResponse{Body: "pong"}})
+
+	status, body := doStubRequest(t, http.MethodGet, stubServer.URL+"/ping", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "pong", body)
+}
+
+func TestHTTPStubServerMostRecentStubWins(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/v1/users/*"}, Response: HTTPStubResponse{Body: "default"}})
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/v1/users/42"}, Response: HTTPStubResponse{Body: "override"}})
+
+	_, body := doStubRequest(t, http.MethodGet, stubServer.URL+"/v1/users/42", "", nil)
+	assert.Equal(t, "override", body)
+	_, body = doStubRequest(t, http.MethodGet, stubServer.URL+"/v1/users/7", "", nil)
+	assert.Equal(t, "default", body)
+}
+
+func TestHTTPStubServerTimes(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(
+		HTTPStub{Request: HTTPStubMatcher{Path: "/token"}, Response: HTTPStubResponse{Body: "fallback"}},
+		HTTPStub{Request: HTTPStubMatcher{Path: "/token"}, Response: HTTPStubResponse{Status: http.StatusServiceUnavailable}, Times: 2},
+	)
+
+	for i := 0; i < 2; i++ {
+		status, _ := doStubRequest(t, http.MethodGet, stubServer.URL+"/token", "", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, status, "request #%d", i+1)
+	}
+	status, body := doStubRequest(t, http.MethodGet, stubServer.URL+"/token", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "fallback", body)
+}
+
+func TestHTTPStubServerTimesExhausted(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/once"}, Times: 1})
+
+	status, _ := doStubRequest(t, http.MethodGet, stubServer.URL+"/once", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = doStubRequest(t, http.MethodGet, stubServer.URL+"/once", "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestHTTPStubServerDelay(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/slow"}, Response: HTTPStubResponse{Delay: delay}})
+
+	start := time.Now()
+	status, _ := doStubRequest(t, http.MethodGet, stubServer.URL+"/slow", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+
+	client := &http.Client{Timeout: delay / 4}
+	_, err := client.Get(stubServer.URL + "/slow")
+	assert.Error(t, err, "client timeout is shorter than the delay")
+}
+
+func TestHTTPStubServerFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault HTTPStubFault
+	}{
+		{name: "close connection", fault: HTTPStubFaultCloseConnection},
+		{name: "malformed response", fault: HTTPStubFaultMalformedResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubServer := runTestHTTPStubServer(t)
+			stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/fault"}, Response: HTTPStubResponse{Fault: tt.fault}})
+
+			// POST requests are not retried by the client, so exactly one request reaches the server:
+			resp, err := http.Post(stubServer.URL+"/fault", "text/plain", strings.NewReader("body"))
+			if err == nil {
+				resp.Body.Close()
+			}
+			assert.Error(t, err)
+			stubServer.AssertReceived(t, HTTPStubMatcher{Path: "/fault"}, 1)
+		})
+	}
+}
+
+func TestHTTPStubServerVerification(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Method: http.MethodPost, Path: "/v1/events"}, Response: HTTPStubResponse{Status: http.StatusCreated}})
+
+	doStubRequest(t, http.MethodPost, stubServer.URL+"/v1/events?source=test", `{"type": "created"}`,
+		http.Header{"X-Request-Id": {"1"}})
+	doStubRequest(t, http.MethodPost, stubServer.URL+"/v1/events", `{"type": "deleted"}`, nil)
+
+	stubServer.AssertReceived(t, HTTPStubMatcher{Method: http.MethodPost, Path: "/v1/events"}, 2)
+	stubServer.AssertReceived(t, HTTPStubMatcher{BodyJSON: `{"type": "created"}`, Query: map[string]string{"source": "test"}}, 1)
+	stubServer.AssertReceived(t, HTTPStubMatcher{Method: http.MethodGet}, 0)
+	stubServer.AssertNoUnmatchedRequests(t)
+
+	received := stubServer.ReceivedRequests(HTTPStubMatcher{Headers: map[string]string{"X-Request-Id": "1"}})
+	require.Len(t, received, 1)
+	assert.JSONEq(t, `{"type": "created"}`, string(received[0].Body))
+}
+
+func TestHTTPStubServerReset(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	stubServer.Stub(HTTPStub{Request: HTTPStubMatcher{Path: "/ping"}})
+	doStubRequest(t, http.MethodGet, stubServer.URL+"/ping", "", nil)
+
+	stubServer.Reset()
+
+	assert.Empty(t, stubServer.Requests())
+	status, _ := doStubRequest(t, http.MethodGet, stubServer.URL+"/ping", "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestHTTPStubServerURLs(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+
+	port := strings.TrimPrefix(stubServer.URL, "http://127.0.0.1:")
+	assert.NotEqual(t, stubServer.URL, port)
+	assert.Equal(t, "http://"+DockerHostGatewayName+":"+port, stubServer.ContainerURL)
+}
+
+func TestHTTPStubServerListensOnLoopbackOnly(t *testing.T) {
+	stubServer := runTestHTTPStubServer(t)
+	port := strings.TrimPrefix(stubServer.URL, "http://127.0.0.1:")
+
+	addrs, err := net.InterfaceAddrs()
+	require.NoError(t, err)
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(ipNet.IP.String(), port), time.Second)
+		if err == nil {
+			conn.Close()
+		}
+		assert.Errorf(t, err, "stub server is reachable at %s", ipNet.IP)
+		return
+	}
+	t.Skip("no non-loopback IPv4 address to check")
+}
+
+func TestHTTPStubServerListenAddress(t *testing.T) {
+	stubServer, closeFn, err := RunHTTPStubServer(WithHTTPStubListenAddress("0.0.0.0:0"))
+	require.NoError(t, err)
+	t.Cleanup(closeFn)
+
+	// the URL of a server listening on all interfaces points to the loopback interface:
+	require.True(t, strings.HasPrefix(stubServer.URL, "http://127.0.0.1:"), stubServer.URL)
+	status, _ := doStubRequest(t, http.MethodGet, stubServer.URL+"/anything", "", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestHTTPStubMatcherString(t *testing.T) {
+	assert.Equal(t, "* *", HTTPStubMatcher{}.String())
+	assert.Equal(t, `POST /v1/* body contains "id"`, HTTPStubMatcher{Method: http.MethodPost, Path: "/v1/*", BodyContains: "id"}.String())
+}
+
+func TestAddDockerHostGateway(t *testing.T) {
+	hostConfig := &container.HostConfig{ExtraHosts: []string{"db:10.0.0.1"}}
+
+	AddDockerHostGateway(hostConfig)
+
+	assert.Equal(t, []string{"db:10.0.0.1", "host.docker.internal:host-gateway"}, hostConfig.ExtraHosts)
+}
@@ -0,0 +1,66 @@
+package integrationtesting
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ReceivedRequests returns the received requests which match the matcher.
+func (s *HTTPStubServer) ReceivedRequests(matcher HTTPStubMatcher) []HTTPStubRequest {
+	var matched []HTTPStubRequest
+	for _, request := range s.Requests() {
+		if matcher.Matches(request) {
+			matched = append(matched, request)
+		}
+	}
+	return matched
+}
+
+// AssertReceived asserts that exactly count requests matching the matcher were received.
+func (s *HTTPStubServer) AssertReceived(t *testing.T, matcher HTTPStubMatcher, count int) bool {
+	t.Helper()
+	matched := s.ReceivedRequests(matcher)
+	if len(matched) != count {
+		return assert.Failf(t, "unexpected number of requests", "expected %d requests matching %s, got %d; received requests:\n%s",
+			count, matcher, len(matched), describeStubRequests(s.Requests()))
+	}
+	return true
+}
+
+// AssertNoUnmatchedRequests asserts that every received request matched a stub.
+func (s *HTTPStubServer) AssertNoUnmatchedRequests(t *testing.T) bool {
+	t.Helper()
+	var unmatched []HTTPStubRequest
+	for _, request := range s.Requests() {
+		if !request.Matched {
+			unmatched = append(unmatched, request)
+		}
+	}
+	if len(unmatched) > 0 {
+		return assert.Failf(t, "unmatched requests", "%d requests matched no stub:\n%s",
+			len(unmatched), describeStubRequests(unmatched))
+	}
+	return true
+}
+
+// describeStubRequests lists the requests for assertion failures.
+func describeStubRequests(requests []HTTPStubRequest) string {
+	if len(requests) == 0 {
+		return "  (none)"
+	}
+	lines := make([]string, 0, len(requests))
+	for _, request := range requests {
+		line := fmt.Sprintf("  %s %s", request.Method, request.Path)
+		if len(request.Query) > 0 {
+			line += fmt.Sprintf(" query=%v", request.Query)
+		}
+		if len(request.Body) > 0 {
+			line += fmt.Sprintf(" body=%q", request.Body)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,234 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// kafkaImageName specifies Docker image name for Kafka.
+	kafkaImageName = "confluentinc/cp-kafka:7.5.3"
+
+	// kafkaStarterScript is a path to the script which configures advertised listeners and starts Kafka.
+	// The container waits for it to appear, since the mapped host port is only known after the container is started.
+	kafkaStarterScript = "/usr/sbin/testcontainers_start.sh"
+	// kafkaClusterID is a KRaft cluster ID, which must be a base64-encoded UUID.
+	kafkaClusterID = "4L6g3nShT-eMCtK--X86sw"
+	// kafkaTopicTimeout specifies how long to wait for created topics to become available.
+	kafkaTopicTimeout = 30 * time.Second
+)
+
+// KafkaDockerInstance is a config with Kafka connection settings.
+type KafkaDockerInstance struct {
+	// Brokers is a list of bootstrap broker addresses reachable from the host, e.g. "localhost:55001".
+	Brokers []string
+}
+
+// KafkaTopic describes a topic to be created with CreateTopics.
+type KafkaTopic struct {
+	// Name is a name of the topic.
+	Name string
+	// Partitions is a number of partitions; it defaults to 1.
+	Partitions int
+	// Configs are topic-level configs, e.g. "cleanup.policy": "compact".
+	Configs map[string]string
+}
+
+// RunKafkaDockerContainer creates new single-node Kafka test container running in KRaft mode (without ZooKeeper)
+// and initializes application repositories. The broker advertises the mapped host port, so it is reachable from tests.
+// Returns cleanup function that must be called.
+func RunKafkaDockerContainer() (KafkaDockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		kafkaInternalPort = "9093"
+		kafkaStarterTmpl  = `#!/bin/bash
+source /etc/confluent/docker/bash-config
+export KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://%s:%s,BROKER://localhost:9092
+sed -i '/KAFKA_ZOOKEEPER_CONNECT/d' /etc/confluent/docker/configure
+echo 'kafka-storage format --ignore-formatted -t "$CLUSTER_ID" -c /etc/kafka/kafka.properties' >> /etc/confluent/docker/configure
+echo '' > /etc/confluent/docker/ensure
+/etc/confluent/docker/configure
+/etc/confluent/docker/launch
+`
+	)
+
+	kafkaPort := nat.Port(kafkaInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        kafkaImageName,
+			ExposedPorts: []string{kafkaPort.Port()},
+			Env: map[string]string{
+				"CLUSTER_ID":                                     kafkaClusterID,
+				"KAFKA_NODE_ID":                                  "1",
+				"KAFKA_PROCESS_ROLES":                            "broker,controller",
+				"KAFKA_LISTENERS":                                "PLAINTEXT://0.0.0.0:9093,BROKER://0.0.0.0:9092,CONTROLLER://0.0.0.0:9094",
+				"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":           "PLAINTEXT:PLAINTEXT,BROKER:PLAINTEXT,CONTROLLER:PLAINTEXT",
+				"KAFKA_INTER_BROKER_LISTENER_NAME":               "BROKER",
+				"KAFKA_CONTROLLER_LISTENER_NAMES":                "CONTROLLER",
+				"KAFKA_CONTROLLER_QUORUM_VOTERS":                 "1@localhost:9094",
+				"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR":         "1",
+				"KAFKA_OFFSETS_TOPIC_NUM_PARTITIONS":             "1",
+				"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR":            "1",
+				"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": "1",
+				"KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS":         "0",
+			},
+			Entrypoint: []string{"sh"},
+			Cmd:        []string{"-c", "while [ ! -f " + kafkaStarterScript + " ]; do sleep 0.1; done; bash " + kafkaStarterScript},
+		},
+		Started: true, // auto-start the container
+	}
+	kafkaContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return KafkaDockerInstance{}, func() {}, fmt.Errorf("kafka container start: %w", err)
+	}
+
+	// Test container clean up function:
+	terminateFn := func() {
+		if err := kafkaContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("failed to terminate Kafka test container: %v", err)
+			return
+		}
+		stdlog.Println("Kafka test container terminated")
+	}
+
+	kafkaHostIP, err := kafkaContainer.Host(ctx)
+	if err != nil {
+		return KafkaDockerInstance{}, terminateFn, fmt.Errorf("map Kafka host: %w", err)
+	}
+
+	kafkaHostPort, err := kafkaContainer.MappedPort(ctx, kafkaPort)
+	if err != nil {
+		return KafkaDockerInstance{}, terminateFn, fmt.Errorf("map Kafka port: %w", err)
+	}
+
+	starterScript := fmt.Sprintf(kafkaStarterTmpl, kafkaHostIP, kafkaHostPort.Port())
+	if err := kafkaContainer.CopyToContainer(ctx, []byte(starterScript), kafkaStarterScript, 0o755); err != nil {
+		return KafkaDockerInstance{}, terminateFn, fmt.Errorf("copy Kafka starter script: %w", err)
+	}
+	if err := wait.ForLog("Kafka Server started").WithStartupTimeout(time.Minute).WaitUntilReady(ctx, kafkaContainer); err != nil {
+		return KafkaDockerInstance{}, terminateFn, fmt.Errorf("wait for Kafka to start: %w", err)
+	}
+
+	broker := net.JoinHostPort(kafkaHostIP, kafkaHostPort.Port())
+	instance := KafkaDockerInstance{
+		Brokers: []string{broker},
+	}
+	stdlog.Printf("Kafka container started, running at: %q\n", broker)
+	return instance, terminateFn, nil
+}
+
+// CreateTopics creates the topics and waits until their partitions have leaders.
+func (k *KafkaDockerInstance) CreateTopics(ctx context.Context, topics ...KafkaTopic) error {
+	topicConfigs := make([]kafka.TopicConfig, 0, len(topics))
+	for _, topic := range topics {
+		partitions := topic.Partitions
+		if partitions == 0 {
+			partitions = 1
+		}
+		topicConfig := kafka.TopicConfig{
+			Topic:             topic.Name,
+			NumPartitions:     partitions,
+			ReplicationFactor: 1,
+		}
+		for name, value := range topic.Configs {
+			topicConfig.ConfigEntries = append(topicConfig.ConfigEntries, kafka.ConfigEntry{ConfigName: name, ConfigValue: value})
+		}
+		topicConfigs = append(topicConfigs, topicConfig)
+	}
+
+	err := k.withControllerConn(ctx, func(conn *kafka.Conn) error {
+		return conn.CreateTopics(topicConfigs...)
+	})
+	if err != nil {
+		return fmt.Errorf("create Kafka topics: %w", err)
+	}
+
+	for _, topic := range topicConfigs {
+		if err := k.waitForTopic(ctx, topic.Topic, topic.NumPartitions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustCreateTopics is like CreateTopics, but panics if the topics can't be created.
+func (k *KafkaDockerInstance) MustCreateTopics(topics ...KafkaTopic) {
+	if err := k.CreateTopics(context.Background(), topics...); err != nil {
+		panic(err)
+	}
+}
+
+// DeleteTopics deletes the topics. Can be used after the tests to clean up all the user's data.
+func (k *KafkaDockerInstance) DeleteTopics(ctx context.Context, topics ...string) error {
+	err := k.withControllerConn(ctx, func(conn *kafka.Conn) error {
+		return conn.DeleteTopics(topics...)
+	})
+	if err != nil {
+		return fmt.Errorf("delete Kafka topics: %w", err)
+	}
+	return nil
+}
+
+// withControllerConn calls fn with a connection to the controller broker, which is required for topic management.
+func (k *KafkaDockerInstance) withControllerConn(ctx context.Context, fn func(conn *kafka.Conn) error) error {
+	conn, err := kafka.DialContext(ctx, "tcp", k.Brokers[0])
+	if err != nil {
+		return fmt.Errorf("dial broker: %w", err)
+	}
+	defer conn.Close()
+
+	controller, err := conn.Controller()
+	if err != nil {
+		return fmt.Errorf("get controller: %w", err)
+	}
+	controllerConn, err := kafka.DialContext(ctx, "tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return fmt.Errorf("dial controller: %w", err)
+	}
+	defer controllerConn.Close()
+
+	return fn(controllerConn)
+}
+
+// waitForTopic waits until all partitions of the topic have leaders, so that messages can be produced.
+func (k *KafkaDockerInstance) waitForTopic(ctx context.Context, topic string, partitions int) error {
+	ctx, cancel := context.WithTimeout(ctx, kafkaTopicTimeout)
+	defer cancel()
+
+	conn, err := kafka.DialContext(ctx, "tcp", k.Brokers[0])
+	if err != nil {
+		return fmt.Errorf("dial broker: %w", err)
+	}
+	defer conn.Close()
+
+	for {
+		readPartitions, err := conn.ReadPartitions(topic)
+		if err == nil && len(readPartitions) == partitions && allPartitionsHaveLeaders(readPartitions) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for Kafka topic %q: %w", topic, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func allPartitionsHaveLeaders(partitions []kafka.Partition) bool {
+	for _, partition := range partitions {
+		if partition.Leader.Host == "" {
+			return false
+		}
+	}
+	return true
+}
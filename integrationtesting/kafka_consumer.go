@@ -0,0 +1,108 @@
+package integrationtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// KafkaTestConsumer collects all messages published to a topic, from its very beginning, for assertions in tests.
+type KafkaTestConsumer struct {
+	topic     string
+	reader    *kafka.Reader
+	collector messageCollector[kafka.Message]
+	mu        sync.Mutex
+	err       error
+}
+
+// Consume starts collecting messages from all partitions of the topic, starting from the earliest offset.
+// The consumer is stopped when the test finishes.
+func (k *KafkaDockerInstance) Consume(t *testing.T, topic string) *KafkaTestConsumer {
+	t.Helper()
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     k.Brokers,
+		GroupID:     fmt.Sprintf("test-consumer-%s-%d", topic, time.Now().UnixNano()),
+		Topic:       topic,
+		StartOffset: kafka.FirstOffset,
+		MaxWait:     100 * time.Millisecond,
+	})
+	consumer := &KafkaTestConsumer{topic: topic, reader: reader}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		consumer.run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		if err := reader.Close(); err != nil {
+			t.Logf("close Kafka consumer of topic %q: %v", topic, err)
+		}
+	})
+	return consumer
+}
+
+// Messages returns a copy of all messages collected so far.
+func (c *KafkaTestConsumer) Messages() []kafka.Message {
+	return c.collector.all()
+}
+
+// WaitForMessage waits until a message matching the predicate is collected and returns it.
+// It returns false if no such message arrives within the timeout.
+func (c *KafkaTestConsumer) WaitForMessage(timeout time.Duration, match func(kafka.Message) bool) (kafka.Message, bool) {
+	return c.collector.wait(timeout, match)
+}
+
+// AssertEventuallyMessageWithKey asserts that a message with the key arrives to the topic within the timeout,
+// and returns it.
+func (c *KafkaTestConsumer) AssertEventuallyMessageWithKey(t *testing.T, key string, timeout time.Duration) (kafka.Message, bool) {
+	t.Helper()
+	message, ok := c.WaitForMessage(timeout, func(message kafka.Message) bool {
+		return string(message.Key) == key
+	})
+	if !ok {
+		assert.Failf(t, "message not received", "no message with key %q arrived to topic %q within %s, %s",
+			key, c.topic, timeout, c.summary())
+	}
+	return message, ok
+}
+
+// AssertEventuallyMessageCount asserts that at least count messages arrive to the topic within the timeout.
+func (c *KafkaTestConsumer) AssertEventuallyMessageCount(t *testing.T, count int, timeout time.Duration) bool {
+	t.Helper()
+	return c.collector.assertCount(t, count, timeout, fmt.Sprintf("topic %q", c.topic), c.summary)
+}
+
+func (c *KafkaTestConsumer) run(ctx context.Context) {
+	for {
+		message, err := c.reader.ReadMessage(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				c.mu.Lock()
+				c.err = err
+				c.mu.Unlock()
+			}
+			return
+		}
+		c.collector.add(message)
+	}
+}
+
+// summary describes collected messages and the consumer error, if any, for assertion failures.
+func (c *KafkaTestConsumer) summary() string {
+	summary := c.collector.status()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		summary += fmt.Sprintf(", consumer failed: %v", c.err)
+	}
+	return summary
+}
@@ -0,0 +1,120 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// mailpitImageName specifies Docker image name for Mailpit, an SMTP server which captures all emails.
+const mailpitImageName = "axllent/mailpit:v1.12"
+
+// MailpitDockerInstance is a config with Mailpit connection settings.
+type MailpitDockerInstance struct {
+	// SMTPHost is a host of the SMTP server. It accepts any credentials and doesn't require TLS.
+	SMTPHost string
+	// SMTPPort is a port of the SMTP server.
+	SMTPPort int
+	// APIURL is a base URL of the HTTP API and web UI, e.g. "http://localhost:8025".
+	APIURL string
+}
+
+// MailpitOption configures the Mailpit test container started by RunMailpitDockerContainer.
+type MailpitOption func(*mailpitConfig)
+
+// mailpitConfig holds Mailpit test container settings.
+type mailpitConfig struct {
+	image string
+}
+
+// WithMailpitImage overrides the default Mailpit Docker image.
+func WithMailpitImage(image string) MailpitOption {
+	return func(cfg *mailpitConfig) {
+		cfg.image = image
+	}
+}
+
+// RunMailpitDockerContainer creates new Mailpit test container, which captures all emails sent to its SMTP server,
+// and initializes application repositories.
+// Returns cleanup function that must be called.
+func RunMailpitDockerContainer(opts ...MailpitOption) (MailpitDockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		smtpInternalPort = "1025"
+		httpInternalPort = "8025"
+
+		apiURLTemplate = "http://%s:%s"
+	)
+
+	cfg := mailpitConfig{
+		image: mailpitImageName,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	smtpPort := nat.Port(smtpInternalPort + "/tcp")
+	httpPort := nat.Port(httpInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        cfg.image,
+			ExposedPorts: []string{smtpPort.Port(), httpPort.Port()},
+			Env: map[string]string{
+				// accept any SMTP credentials, so the application can be configured as for a real server:
+				"MP_SMTP_AUTH_ACCEPT_ANY":     "true",
+				"MP_SMTP_AUTH_ALLOW_INSECURE": "true",
+			},
+			WaitingFor: wait.ForAll(
+				wait.ForListeningPort(smtpPort),
+				wait.ForHTTP("/api/v1/messages").WithPort(httpPort),
+			),
+		},
+		Started: true, // auto-start the container
+	}
+	mailpitContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return MailpitDockerInstance{}, func() {}, fmt.Errorf("mailpit container start: %w", err)
+	}
+
+	// Test container clean up function:
+	terminateFn := func() {
+		if err := mailpitContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("failed to terminate Mailpit test container: %v", err)
+			return
+		}
+		stdlog.Println("Mailpit test container terminated")
+	}
+
+	mailpitHostIP, err := mailpitContainer.Host(ctx)
+	if err != nil {
+		return MailpitDockerInstance{}, terminateFn, fmt.Errorf("map Mailpit host: %w", err)
+	}
+
+	smtpHostPort, err := mailpitContainer.MappedPort(ctx, smtpPort)
+	if err != nil {
+		return MailpitDockerInstance{}, terminateFn, fmt.Errorf("map Mailpit SMTP port: %w", err)
+	}
+
+	httpHostPort, err := mailpitContainer.MappedPort(ctx, httpPort)
+	if err != nil {
+		return MailpitDockerInstance{}, terminateFn, fmt.Errorf("map Mailpit HTTP port: %w", err)
+	}
+
+	instance := MailpitDockerInstance{
+		SMTPHost: mailpitHostIP,
+		SMTPPort: smtpHostPort.Int(),
+		APIURL:   fmt.Sprintf(apiURLTemplate, mailpitHostIP, httpHostPort.Port()),
+	}
+	stdlog.Printf("Mailpit container started, SMTP server running at: %q, API at: %q\n",
+		fmt.Sprintf("%s:%d", instance.SMTPHost, instance.SMTPPort), instance.APIURL)
+	return instance, terminateFn, nil
+}
+
+// doAPIRequest sends a request to the HTTP API and returns the response body.
+func (m *MailpitDockerInstance) doAPIRequest(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return doHTTPAPIRequest(ctx, nil, m.APIURL, nil, method, path, body, contentTypeJSON)
+}
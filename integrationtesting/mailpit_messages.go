@@ -0,0 +1,178 @@
+package integrationtesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// mailpitPollInterval specifies how often captured messages are checked by assertions.
+	mailpitPollInterval = 100 * time.Millisecond
+	// mailpitMessagesLimit specifies the maximum number of the latest messages returned by Messages.
+	mailpitMessagesLimit = 1000
+)
+
+// MailpitAddress is an email address with an optional display name.
+type MailpitAddress struct {
+	Name    string `json:"Name"`
+	Address string `json:"Address"`
+}
+
+// MailpitMessageSummary describes a captured message, as returned in the message list.
+type MailpitMessageSummary struct {
+	// ID is a Mailpit message ID, which is used to fetch the message.
+	ID string `json:"ID"`
+	// MessageID is a value of the "Message-ID" header.
+	MessageID string           `json:"MessageID"`
+	From      MailpitAddress   `json:"From"`
+	To        []MailpitAddress `json:"To"`
+	Cc        []MailpitAddress `json:"Cc"`
+	Bcc       []MailpitAddress `json:"Bcc"`
+	Subject   string           `json:"Subject"`
+	Created   time.Time        `json:"Created"`
+	// Attachments is a number of attachments.
+	Attachments int `json:"Attachments"`
+}
+
+// SentTo reports whether the message was sent to the address, as one of To, Cc or Bcc recipients.
+// Addresses are compared case-insensitively.
+func (s MailpitMessageSummary) SentTo(address string) bool {
+	for _, recipients := range [][]MailpitAddress{s.To, s.Cc, s.Bcc} {
+		for _, recipient := range recipients {
+			if strings.EqualFold(recipient.Address, address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MailpitMessage is a captured message with its bodies.
+type MailpitMessage struct {
+	ID        string           `json:"ID"`
+	MessageID string           `json:"MessageID"`
+	From      MailpitAddress   `json:"From"`
+	To        []MailpitAddress `json:"To"`
+	Cc        []MailpitAddress `json:"Cc"`
+	Bcc       []MailpitAddress `json:"Bcc"`
+	ReplyTo   []MailpitAddress `json:"ReplyTo"`
+	Subject   string           `json:"Subject"`
+	Date      time.Time        `json:"Date"`
+	// Text is a plain text body.
+	Text string `json:"Text"`
+	// HTML is an HTML body.
+	HTML        string              `json:"HTML"`
+	Attachments []MailpitAttachment `json:"Attachments"`
+}
+
+// MailpitAttachment describes a message attachment. Its content can be fetched with Attachment.
+type MailpitAttachment struct {
+	PartID      string `json:"PartID"`
+	FileName    string `json:"FileName"`
+	ContentType string `json:"ContentType"`
+	Size        int    `json:"Size"`
+}
+
+// Messages returns summaries of the latest captured messages, newest first.
+func (m *MailpitDockerInstance) Messages(ctx context.Context) ([]MailpitMessageSummary, error) {
+	body, err := m.doAPIRequest(ctx, http.MethodGet, fmt.Sprintf("/api/v1/messages?limit=%d", mailpitMessagesLimit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("list Mailpit messages: %w", err)
+	}
+	var resp struct {
+		Messages []MailpitMessageSummary `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("decode Mailpit messages: %w", err)
+	}
+	return resp.Messages, nil
+}
+
+// Message returns the captured message with its bodies and the list of attachments.
+func (m *MailpitDockerInstance) Message(ctx context.Context, id string) (MailpitMessage, error) {
+	body, err := m.doAPIRequest(ctx, http.MethodGet, "/api/v1/message/"+url.PathEscape(id), nil)
+	if err != nil {
+		return MailpitMessage{}, fmt.Errorf("get Mailpit message %q: %w", id, err)
+	}
+	var message MailpitMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		return MailpitMessage{}, fmt.Errorf("decode Mailpit message %q: %w", id, err)
+	}
+	return message, nil
+}
+
+// Attachment returns the decoded content of the message attachment.
+func (m *MailpitDockerInstance) Attachment(ctx context.Context, messageID, partID string) ([]byte, error) {
+	path := "/api/v1/message/" + url.PathEscape(messageID) + "/part/" + url.PathEscape(partID)
+	content, err := m.doAPIRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get Mailpit attachment %q of message %q: %w", partID, messageID, err)
+	}
+	return content, nil
+}
+
+// DeleteMessages deletes all captured messages.
+// Can be used after the tests to clean up all the user's data.
+func (m *MailpitDockerInstance) DeleteMessages(ctx context.Context) error {
+	if _, err := m.doAPIRequest(ctx, http.MethodDelete, "/api/v1/messages", nil); err != nil {
+		return fmt.Errorf("delete Mailpit messages: %w", err)
+	}
+	return nil
+}
+
+// MustDeleteMessages is like DeleteMessages, but panics if the messages can't be deleted.
+func (m *MailpitDockerInstance) MustDeleteMessages() {
+	if err := m.DeleteMessages(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+// AssertEventuallyEmail asserts that an email to the address with the subject is captured within the timeout,
+// and returns it along with its bodies.
+func (m *MailpitDockerInstance) AssertEventuallyEmail(t *testing.T, to, subject string, timeout time.Duration) (MailpitMessage, bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var (
+		summaries []MailpitMessageSummary
+		err       error
+	)
+	for {
+		latest, listErr := m.Messages(ctx)
+		if ctx.Err() == nil {
+			// keep the results of the last complete request for the failure message:
+			summaries, err = latest, listErr
+		}
+		if listErr == nil {
+			for _, summary := range latest {
+				if summary.SentTo(to) && summary.Subject == subject {
+					message, err := m.Message(context.Background(), summary.ID)
+					if !assert.NoError(t, err) {
+						return MailpitMessage{}, false
+					}
+					return message, true
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return MailpitMessage{}, assert.Failf(t, "email not received", "no email to %q with subject %q captured within %s: %v",
+					to, subject, timeout, err)
+			}
+			return MailpitMessage{}, assert.Failf(t, "email not received", "no email to %q with subject %q captured within %s, %d emails captured",
+				to, subject, timeout, len(summaries))
+		case <-time.After(mailpitPollInterval):
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package integrationtesting
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// messageCollectorPollInterval specifies how often collected messages are checked by assertions.
+const messageCollectorPollInterval = 50 * time.Millisecond
+
+// messageCollector collects messages received in the background, e.g. by a test consumer, for assertions in tests.
+// The zero value is ready to use.
+type messageCollector[T any] struct {
+	mu       sync.Mutex
+	messages []T
+}
+
+// add appends the received message.
+func (c *messageCollector[T]) add(message T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, message)
+}
+
+// all returns a copy of all messages collected so far.
+func (c *messageCollector[T]) all() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]T(nil), c.messages...)
+}
+
+// count returns the number of messages collected so far.
+func (c *messageCollector[T]) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.messages)
+}
+
+// wait waits until a message matching the predicate is collected and returns it.
+// It returns false if no such message arrives within the timeout.
+func (c *messageCollector[T]) wait(timeout time.Duration, match func(T) bool) (T, bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, message := range c.all() {
+			if match(message) {
+				return message, true
+			}
+		}
+		if time.Now().After(deadline) {
+			var zero T
+			return zero, false
+		}
+		time.Sleep(messageCollectorPollInterval)
+	}
+}
+
+// assertCount asserts that at least count messages are collected within the timeout.
+// The source, e.g. `topic "orders"`, and the status, e.g. "3 messages received", are included in the failure message.
+func (c *messageCollector[T]) assertCount(t *testing.T, count int, timeout time.Duration, source string, status func() string) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for c.count() < count {
+		if time.Now().After(deadline) {
+			return assert.Failf(t, "messages not received", "expected at least %d messages in %s within %s, %s",
+				count, source, timeout, status())
+		}
+		time.Sleep(messageCollectorPollInterval)
+	}
+	return true
+}
+
+// status describes the number of collected messages for assertion failures.
+func (c *messageCollector[T]) status() string {
+	return fmt.Sprintf("%d messages received", c.count())
+}
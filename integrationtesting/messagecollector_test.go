@@ -0,0 +1,50 @@
+package integrationtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageCollectorWait(t *testing.T) {
+	var collector messageCollector[string]
+	go func() {
+		for _, message := range []string{"created", "updated", "deleted"} {
+			time.Sleep(10 * time.Millisecond)
+			collector.add(message)
+		}
+	}()
+
+	message, ok := collector.wait(time.Second, func(message string) bool { return message == "updated" })
+	assert.True(t, ok)
+	assert.Equal(t, "updated", message)
+
+	message, ok = collector.wait(100*time.Millisecond, func(message string) bool { return message == "archived" })
+	assert.False(t, ok)
+	assert.Empty(t, message)
+}
+
+func TestMessageCollectorAssertCount(t *testing.T) {
+	var collector messageCollector[int]
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(10 * time.Millisecond)
+			collector.add(i)
+		}
+	}()
+
+	assert.True(t, collector.assertCount(t, 3, time.Second, `topic "test"`, collector.status))
+	assert.Equal(t, []int{0, 1, 2}, collector.all())
+	assert.Equal(t, "3 messages received", collector.status())
+}
+
+func TestMessageCollectorAllReturnsCopy(t *testing.T) {
+	var collector messageCollector[string]
+	collector.add("created")
+
+	messages := collector.all()
+	messages[0] = "modified"
+
+	assert.Equal(t, []string{"created"}, collector.all())
+}
@@ -0,0 +1,254 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"net"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// minIOImageName specifies Docker image name for MinIO.
+	minIOImageName = "minio/minio:RELEASE.2023-12-23T07-19-11Z"
+
+	// minIODefaultRegion is a region reported by MinIO unless set with WithMinIORegion.
+	minIODefaultRegion = "us-east-1"
+)
+
+// MinIODockerInstance is a config with S3-compatible MinIO connection settings.
+type MinIODockerInstance struct {
+	// Endpoint is an S3 API endpoint reachable from the host, e.g. "localhost:9000". TLS is not used.
+	Endpoint string
+	// AccessKey is an access key ID used for S3 API requests.
+	AccessKey string
+	// SecretKey is a secret access key used for S3 API requests.
+	SecretKey string
+	// Region is a region of the server, which must be used for request signing.
+	Region      string
+	minIOClient *minio.Client
+}
+
+// Client returns a MinIO client connected to the test container.
+func (m *MinIODockerInstance) Client() *minio.Client {
+	return m.minIOClient
+}
+
+// MinIOBucket describes a bucket to be created at start-up with WithMinIOBuckets.
+type MinIOBucket struct {
+	// Name is a name of the bucket.
+	Name string
+	// Versioning enables versioning of the bucket objects.
+	Versioning bool
+	// ObjectLocking enables object locking (WORM) for the bucket. It implies Versioning.
+	ObjectLocking bool
+}
+
+// MinIOOption configures the MinIO test container started by RunMinIODockerContainer.
+type MinIOOption func(*minIOConfig)
+
+// minIOConfig holds MinIO test container settings.
+type minIOConfig struct {
+	image   string
+	region  string
+	buckets []MinIOBucket
+}
+
+// WithMinIOImage overrides the default MinIO Docker image.
+func WithMinIOImage(image string) MinIOOption {
+	return func(cfg *minIOConfig) {
+		cfg.image = image
+	}
+}
+
+// WithMinIORegion overrides the default "us-east-1" region of the server.
+func WithMinIORegion(region string) MinIOOption {
+	return func(cfg *minIOConfig) {
+		cfg.region = region
+	}
+}
+
+// WithMinIOBuckets creates the buckets at start-up.
+func WithMinIOBuckets(buckets ...MinIOBucket) MinIOOption {
+	return func(cfg *minIOConfig) {
+		cfg.buckets = append(cfg.buckets, buckets...)
+	}
+}
+
+// RunMinIODockerContainer creates new MinIO test container and initializes application repositories.
+// Returns cleanup function that must be called.
+func RunMinIODockerContainer(opts ...MinIOOption) (MinIODockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		minIOInternalPort = "9000"
+
+		accessKey = "testuser"
+		secretKey = "testpassword"
+	)
+
+	cfg := minIOConfig{
+		image:  minIOImageName,
+		region: minIODefaultRegion,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	minIOPort := nat.Port(minIOInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        cfg.image,
+			ExposedPorts: []string{minIOPort.Port()},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     accessKey,
+				"MINIO_ROOT_PASSWORD": secretKey,
+				"MINIO_SITE_REGION":   cfg.region,
+			},
+			Cmd:        []string{"server", "/data"},
+			WaitingFor: wait.ForHTTP("/minio/health/live").WithPort(minIOPort),
+		},
+		Started: true, // auto-start the container
+	}
+	minIOContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return MinIODockerInstance{}, func() {}, fmt.Errorf("minIO container start: %w", err)
+	}
+
+	// Test container clean up function:
+	terminateFn := func() {
+		if err := minIOContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("failed to terminate MinIO test container: %v", err)
+			return
+		}
+		stdlog.Println("MinIO test container terminated")
+	}
+
+	minIOHostIP, err := minIOContainer.Host(ctx)
+	if err != nil {
+		return MinIODockerInstance{}, terminateFn, fmt.Errorf("map MinIO host: %w", err)
+	}
+
+	minIOHostPort, err := minIOContainer.MappedPort(ctx, minIOPort)
+	if err != nil {
+		return MinIODockerInstance{}, terminateFn, fmt.Errorf("map MinIO port: %w", err)
+	}
+
+	endpoint := net.JoinHostPort(minIOHostIP, minIOHostPort.Port())
+
+	// setup MinIO client:
+	minIOClient, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Region: cfg.region,
+	})
+	if err != nil {
+		return MinIODockerInstance{}, terminateFn, fmt.Errorf("failed to create MinIO client: %w", err)
+	}
+
+	instance := MinIODockerInstance{
+		Endpoint:    endpoint,
+		AccessKey:   accessKey,
+		SecretKey:   secretKey,
+		Region:      cfg.region,
+		minIOClient: minIOClient,
+	}
+	for _, bucket := range cfg.buckets {
+		if err := instance.createBucket(ctx, bucket); err != nil {
+			return MinIODockerInstance{}, terminateFn, err
+		}
+	}
+
+	stdlog.Printf("MinIO container started, running at: %q\n", endpoint)
+	return instance, terminateFn, nil
+}
+
+// createBucket creates the bucket and enables versioning, if requested.
+func (m *MinIODockerInstance) createBucket(ctx context.Context, bucket MinIOBucket) error {
+	err := m.minIOClient.MakeBucket(ctx, bucket.Name, minio.MakeBucketOptions{
+		Region:        m.Region,
+		ObjectLocking: bucket.ObjectLocking,
+	})
+	if err != nil {
+		return fmt.Errorf("create MinIO bucket %q: %w", bucket.Name, err)
+	}
+	// object locking enables versioning on its own:
+	if bucket.Versioning && !bucket.ObjectLocking {
+		if err := m.minIOClient.EnableVersioning(ctx, bucket.Name); err != nil {
+			return fmt.Errorf("enable versioning of MinIO bucket %q: %w", bucket.Name, err)
+		}
+	}
+	return nil
+}
+
+// EmptyBuckets deletes all objects, including all their versions and delete markers, from all buckets.
+// The buckets themselves are kept. Governance-mode retention is bypassed,
+// but objects under compliance-mode retention or a legal hold can't be deleted.
+// Can be used after the tests to clean up all the user's data.
+func (m *MinIODockerInstance) EmptyBuckets(ctx context.Context) error {
+	buckets, err := m.minIOClient.ListBuckets(ctx)
+	if err != nil {
+		return fmt.Errorf("list MinIO buckets: %w", err)
+	}
+	for _, bucket := range buckets {
+		if err := m.emptyBucket(ctx, bucket.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustEmptyBuckets is like EmptyBuckets, but panics if the buckets can't be emptied.
+func (m *MinIODockerInstance) MustEmptyBuckets() {
+	if err := m.EmptyBuckets(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+// emptyBucket deletes all object versions from the bucket.
+func (m *MinIODockerInstance) emptyBucket(ctx context.Context, bucket string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := m.minIOClient.ListObjects(ctx, bucket, minio.ListObjectsOptions{
+		Recursive:    true,
+		WithVersions: true,
+	})
+
+	var listErr error
+	toRemove := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(toRemove)
+		for object := range objects {
+			if object.Err != nil {
+				listErr = object.Err
+				return
+			}
+			select {
+			case toRemove <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	removeErrs := m.minIOClient.RemoveObjects(ctx, bucket, toRemove, minio.RemoveObjectsOptions{GovernanceBypass: true})
+	// the channel is drained to the end, so that the removal goroutine doesn't leak:
+	var removeErr error
+	for result := range removeErrs {
+		if removeErr == nil {
+			removeErr = fmt.Errorf("delete MinIO object %q (version %q) from bucket %q: %w", result.ObjectName, result.VersionID, bucket, result.Err)
+			cancel() // stops listing the rest of the objects
+		}
+	}
+	if removeErr != nil {
+		return removeErr
+	}
+	if listErr != nil {
+		return fmt.Errorf("list MinIO objects in bucket %q: %w", bucket, listErr)
+	}
+	return nil
+}
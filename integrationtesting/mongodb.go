@@ -2,17 +2,29 @@ package integrationtesting
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509/pkix"
 	"fmt"
 	stdlog "log"
+	"net/url"
+	"path/filepath"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
 	// mongoImageName specifies Docker image name for MongoDB.
 	mongoImageName = "mongo:4.2.21"
+
+	// mongoInitScriptsDir specifies a directory inside the MongoDB container with initialization scripts.
+	mongoInitScriptsDir = "/docker-entrypoint-initdb.d/"
+	// mongoTLSDir specifies a directory inside the MongoDB container where TLS certificates are mounted.
+	mongoTLSDir = "/etc/mongo/tls/"
 )
 
 // MongoDockerInstance is a config with MongoDB connection settings.
@@ -20,40 +32,172 @@ type MongoDockerInstance struct {
 	ConnURL  string
 	UserName string
 	UserPass string
+	// DbName is a name of the initial database; it is empty unless set with WithMongoDatabase.
+	DbName string
+	// TLSConfig is a client TLS config trusting the test CA and presenting the test client certificate.
+	// It is nil unless the container was started with WithMongoTLS or WithMongoX509Auth.
+	TLSConfig   *tls.Config
+	mongoClient *mongo.Client
+}
+
+// Client returns a MongoDB client connected to the test container.
+func (m *MongoDockerInstance) Client() *mongo.Client {
+	return m.mongoClient
+}
+
+// MongoOption configures the MongoDB test container started by RunMongoDockerContainer.
+type MongoOption func(*mongoConfig)
+
+// mongoConfig holds MongoDB test container settings.
+type mongoConfig struct {
+	image       string
+	userName    string
+	userPass    string
+	dbName      string
+	initScripts []string
+	tls         bool
+	x509Auth    bool
+}
+
+// WithMongoImage overrides the default MongoDB Docker image, e.g. "mongo:7.0".
+func WithMongoImage(image string) MongoOption {
+	return func(cfg *mongoConfig) {
+		cfg.image = image
+	}
+}
+
+// WithMongoCredentials overrides the default root user name and password.
+func WithMongoCredentials(userName, userPass string) MongoOption {
+	return func(cfg *mongoConfig) {
+		cfg.userName = userName
+		cfg.userPass = userPass
+	}
+}
+
+// WithMongoDatabase sets the initial database: initialization scripts are executed against it
+// and the returned connection URL points to it.
+func WithMongoDatabase(dbName string) MongoOption {
+	return func(cfg *mongoConfig) {
+		cfg.dbName = dbName
+	}
+}
+
+// WithMongoInitScripts mounts the given "*.js" or "*.sh" files into "/docker-entrypoint-initdb.d/",
+// so that they are executed in alphabetical order of their file names when the container is created.
+func WithMongoInitScripts(paths ...string) MongoOption {
+	return func(cfg *mongoConfig) {
+		cfg.initScripts = append(cfg.initScripts, paths...)
+	}
+}
+
+// WithMongoTLS starts MongoDB with TLS required for all connections.
+// A throwaway CA, server and client certificates are generated at start-up;
+// the returned connection URL and TLS config trust that CA.
+func WithMongoTLS() MongoOption {
+	return func(cfg *mongoConfig) {
+		cfg.tls = true
+	}
+}
+
+// WithMongoX509Auth starts MongoDB with TLS required and returns a connection URL
+// which authenticates with the generated client certificate using the MONGODB-X509 mechanism.
+func WithMongoX509Auth() MongoOption {
+	return func(cfg *mongoConfig) {
+		cfg.tls = true
+		cfg.x509Auth = true
+	}
 }
 
 // RunMongoDockerContainer creates new MongoDB test container and initializes application repositories.
 // Returns cleanup function that must be called.
-func RunMongoDockerContainer() (MongoDockerInstance, func(), error) {
+func RunMongoDockerContainer(opts ...MongoOption) (MongoDockerInstance, func(), error) {
 	ctx := context.Background()
 	const (
 		mongoInternalPort = "27017"
 
-		userName                   = "root"
-		userPass                   = "pass"
-		mongoConnectionURLTemplate = "mongodb://%s:%s@%s:%s/?connect=direct"
+		defaultUserName            = "root"
+		defaultUserPass            = "pass"
+		mongoConnectionURLTemplate = "mongodb://%s@%s:%s/%s?%s"
 	)
 
+	cfg := mongoConfig{
+		image:    mongoImageName,
+		userName: defaultUserName,
+		userPass: defaultUserPass,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	mongoPort := nat.Port(mongoInternalPort + "/tcp")
 	containerRequest := testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
-			Image:        mongoImageName,
+			Image:        cfg.image,
 			ExposedPorts: []string{mongoPort.Port()},
 			Env: map[string]string{
-				"MONGO_INITDB_ROOT_USERNAME": userName,
-				"MONGO_INITDB_ROOT_PASSWORD": userPass,
+				"MONGO_INITDB_ROOT_USERNAME": cfg.userName,
+				"MONGO_INITDB_ROOT_PASSWORD": cfg.userPass,
 			},
 			WaitingFor: wait.ForListeningPort(mongoPort),
 		},
 		Started: true, // auto-start the container
 	}
+	if cfg.dbName != "" {
+		containerRequest.Env["MONGO_INITDB_DATABASE"] = cfg.dbName
+	}
+	for _, script := range cfg.initScripts {
+		containerRequest.Files = append(containerRequest.Files, testcontainers.ContainerFile{
+			HostFilePath:      script,
+			ContainerFilePath: mongoInitScriptsDir + filepath.Base(script),
+			FileMode:          0o755,
+		})
+	}
+
+	var certs testCertificates
+	if cfg.tls {
+		var err error
+		certs, err = generateMongoCertificates(ctx)
+		if err != nil {
+			return MongoDockerInstance{}, func() {}, err
+		}
+		containerRequest.Files = append(containerRequest.Files,
+			testcontainers.ContainerFile{HostFilePath: certs.CAFile, ContainerFilePath: mongoTLSDir + testCAFileName, FileMode: 0o644},
+			testcontainers.ContainerFile{HostFilePath: certs.ServerFile, ContainerFilePath: mongoTLSDir + testServerCertFileName, FileMode: 0o644},
+		)
+		containerRequest.Cmd = []string{
+			"--tlsMode", "requireTLS",
+			"--tlsCAFile", mongoTLSDir + testCAFileName,
+			"--tlsCertificateKeyFile", mongoTLSDir + testServerCertFileName,
+		}
+		if !cfg.x509Auth {
+			containerRequest.Cmd = append(containerRequest.Cmd, "--tlsAllowConnectionsWithoutCertificates")
+		}
+	}
+	removeCertsFn := func() {
+		if certs.Dir == "" {
+			return
+		}
+		if err := certs.Remove(); err != nil {
+			stdlog.Printf("failed to remove MongoDB test certificates: %v", err)
+		}
+	}
+
 	mongoContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
 	if err != nil {
+		removeCertsFn()
 		return MongoDockerInstance{}, func() {}, fmt.Errorf("mongoDB container start: %w", err)
 	}
 
+	var mongoClient *mongo.Client
+
 	// Test container clean up function:
 	terminateFn := func() {
+		defer removeCertsFn()
+		if mongoClient != nil {
+			if err := mongoClient.Disconnect(ctx); err != nil {
+				stdlog.Printf("failed to disconnect from MongoDB test container: %v", err)
+			}
+		}
 		if err := mongoContainer.Terminate(ctx); err != nil {
 			stdlog.Printf("failed to terminate MongoDB test container: %v", err)
 			return
@@ -71,12 +215,80 @@ func RunMongoDockerContainer() (MongoDockerInstance, func(), error) {
 		return MongoDockerInstance{}, terminateFn, fmt.Errorf("map MongoDB port: %w", err)
 	}
 
-	mongoURL := fmt.Sprintf(mongoConnectionURLTemplate, userName, userPass, mongoHostIP, mongoHostPort.Port())
+	query := url.Values{}
+	query.Set("connect", "direct")
+	if cfg.dbName != "" {
+		// the root user is always created in the admin database:
+		query.Set("authSource", "admin")
+	}
+	if cfg.tls {
+		query.Set("tls", "true")
+		query.Set("tlsCAFile", certs.CAFile)
+		query.Set("tlsCertificateKeyFile", certs.ClientFile)
+	}
+	rootUser := url.UserPassword(cfg.userName, cfg.userPass).String()
+	rootURL := fmt.Sprintf(mongoConnectionURLTemplate, rootUser, mongoHostIP, mongoHostPort.Port(), cfg.dbName, query.Encode())
+
+	// setup MongoDB client:
+	mongoClient, err = mongo.Connect(ctx, options.Client().ApplyURI(rootURL))
+	if err != nil {
+		return MongoDockerInstance{}, terminateFn, fmt.Errorf("failed to create MongoDB client: %w", err)
+	}
+	if err := mongoClient.Ping(ctx, nil); err != nil {
+		return MongoDockerInstance{}, terminateFn, fmt.Errorf("ping MongoDB: %w", err)
+	}
+
 	instance := MongoDockerInstance{
-		ConnURL:  mongoURL,
-		UserName: userName,
-		UserPass: userPass,
+		ConnURL:     rootURL,
+		UserName:    cfg.userName,
+		UserPass:    cfg.userPass,
+		DbName:      cfg.dbName,
+		TLSConfig:   certs.ClientTLSConfig,
+		mongoClient: mongoClient,
+	}
+	if cfg.x509Auth {
+		// register the client certificate subject as a user in the $external database:
+		createUser := bson.D{
+			{Key: "createUser", Value: certs.ClientSubject},
+			{Key: "roles", Value: bson.A{bson.D{{Key: "role", Value: "root"}, {Key: "db", Value: "admin"}}}},
+		}
+		if err := mongoClient.Database("$external").RunCommand(ctx, createUser).Err(); err != nil {
+			return MongoDockerInstance{}, terminateFn, fmt.Errorf("create MongoDB x.509 user: %w", err)
+		}
+
+		query.Set("authMechanism", "MONGODB-X509")
+		query.Set("authSource", "$external")
+		instance.ConnURL = fmt.Sprintf("mongodb://%s:%s/%s?%s", mongoHostIP, mongoHostPort.Port(), cfg.dbName, query.Encode())
+		instance.UserName = certs.ClientSubject
+		instance.UserPass = ""
 	}
-	stdlog.Printf("MongoDB container started, running at: %q\n", mongoURL)
+
+	stdlog.Printf("MongoDB container started, running at: %q\n", instance.ConnURL)
 	return instance, terminateFn, nil
 }
+
+// generateMongoCertificates creates throwaway TLS certificates for MongoDB test container.
+// The server certificate is valid for the Docker host, so that host name verification succeeds.
+func generateMongoCertificates(ctx context.Context) (testCertificates, error) {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("create Docker provider: %w", err)
+	}
+	defer provider.Close()
+	dockerHost, err := provider.DaemonHost(ctx)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("resolve Docker host: %w", err)
+	}
+	hosts = append(hosts, dockerHost)
+
+	// x.509 client and server certificates must differ in their organization,
+	// otherwise MongoDB treats the client as a cluster member:
+	serverSubject := pkix.Name{CommonName: "mongodb", Organization: []string{"testcontainers-server"}}
+	clientSubject := pkix.Name{CommonName: "mongodb-client", Organization: []string{"testcontainers-client"}}
+	certs, err := generateTestCertificates(serverSubject, clientSubject, hosts...)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("generate MongoDB certificates: %w", err)
+	}
+	return certs, nil
+}
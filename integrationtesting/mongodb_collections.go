@@ -0,0 +1,173 @@
+package integrationtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoNamespaceExistsCode is a MongoDB error code returned when creating a collection that already exists.
+const mongoNamespaceExistsCode = 48
+
+// MongoCollectionDefinition describes a MongoDB collection with its validation rules and indexes.
+//
+// Definitions are stored as MongoDB Extended JSON, for example:
+//
+//	{
+//	  "name": "users",
+//	  "validator": {"$jsonSchema": {"bsonType": "object", "required": ["email"]}},
+//	  "validationLevel": "strict",
+//	  "validationAction": "error",
+//	  "indexes": [
+//	    {"keys": {"email": 1}, "name": "email_unique", "unique": true},
+//	    {"keys": {"createdAt": 1}, "expireAfterSeconds": 3600}
+//	  ]
+//	}
+type MongoCollectionDefinition struct {
+	// Name is a name of the collection.
+	Name string `bson:"name"`
+	// Validator is a validator document, usually with a "$jsonSchema" expression.
+	Validator bson.D `bson:"validator,omitempty"`
+	// ValidationLevel is one of "off", "strict" or "moderate".
+	ValidationLevel string `bson:"validationLevel,omitempty"`
+	// ValidationAction is one of "error" or "warn".
+	ValidationAction string `bson:"validationAction,omitempty"`
+	// Indexes is a list of indexes to create on the collection.
+	Indexes []MongoIndexDefinition `bson:"indexes,omitempty"`
+}
+
+// MongoIndexDefinition describes a single MongoDB index.
+type MongoIndexDefinition struct {
+	// Keys is an ordered index specification, e.g. {"lastName": 1, "firstName": 1}.
+	Keys bson.D `bson:"keys"`
+	// Name is an optional name of the index.
+	Name string `bson:"name,omitempty"`
+	// Unique makes the index unique.
+	Unique bool `bson:"unique,omitempty"`
+	// Sparse makes the index sparse.
+	Sparse bool `bson:"sparse,omitempty"`
+	// ExpireAfterSeconds turns the index into a TTL index.
+	ExpireAfterSeconds *int32 `bson:"expireAfterSeconds,omitempty"`
+	// PartialFilterExpression makes the index partial.
+	PartialFilterExpression bson.D `bson:"partialFilterExpression,omitempty"`
+}
+
+// ApplyCollectionDefinitions creates collections with validators and indexes in the given database
+// from all "*.json" files in the directory, applied in lexical order of file names
+// (e.g. "001_users.json", "002_orders.json").
+//
+// Each file contains a single MongoCollectionDefinition in MongoDB Extended JSON.
+// Collections that already exist get their validation rules updated.
+func (m *MongoDockerInstance) ApplyCollectionDefinitions(ctx context.Context, dbName, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list MongoDB collection definitions in %q: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	db := m.mongoClient.Database(dbName)
+	for _, file := range files {
+		definition, err := readMongoCollectionDefinition(file)
+		if err != nil {
+			return err
+		}
+		if err := applyMongoCollectionDefinition(ctx, db, definition); err != nil {
+			return fmt.Errorf("apply MongoDB collection definition %q: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// MustApplyCollectionDefinitions is like ApplyCollectionDefinitions, but panics if definitions can't be applied.
+func (m *MongoDockerInstance) MustApplyCollectionDefinitions(dbName, dir string) {
+	if err := m.ApplyCollectionDefinitions(context.Background(), dbName, dir); err != nil {
+		panic(err)
+	}
+}
+
+func readMongoCollectionDefinition(file string) (MongoCollectionDefinition, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return MongoCollectionDefinition{}, fmt.Errorf("read MongoDB collection definition: %w", err)
+	}
+
+	var definition MongoCollectionDefinition
+	if err := bson.UnmarshalExtJSON(data, false, &definition); err != nil {
+		return MongoCollectionDefinition{}, fmt.Errorf("parse MongoDB collection definition %q: %w", file, err)
+	}
+	if definition.Name == "" {
+		definition.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	return definition, nil
+}
+
+func applyMongoCollectionDefinition(ctx context.Context, db *mongo.Database, definition MongoCollectionDefinition) error {
+	createOpts := options.CreateCollection()
+	if definition.Validator != nil {
+		createOpts.SetValidator(definition.Validator)
+	}
+	if definition.ValidationLevel != "" {
+		createOpts.SetValidationLevel(definition.ValidationLevel)
+	}
+	if definition.ValidationAction != "" {
+		createOpts.SetValidationAction(definition.ValidationAction)
+	}
+
+	err := db.CreateCollection(ctx, definition.Name, createOpts)
+	var commandErr mongo.CommandError
+	switch {
+	case errors.As(err, &commandErr) && commandErr.Code == mongoNamespaceExistsCode:
+		// collection already exists, update its validation rules instead:
+		collMod := bson.D{{Key: "collMod", Value: definition.Name}}
+		if definition.Validator != nil {
+			collMod = append(collMod, bson.E{Key: "validator", Value: definition.Validator})
+		}
+		if definition.ValidationLevel != "" {
+			collMod = append(collMod, bson.E{Key: "validationLevel", Value: definition.ValidationLevel})
+		}
+		if definition.ValidationAction != "" {
+			collMod = append(collMod, bson.E{Key: "validationAction", Value: definition.ValidationAction})
+		}
+		if err := db.RunCommand(ctx, collMod).Err(); err != nil {
+			return fmt.Errorf("update collection %q: %w", definition.Name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("create collection %q: %w", definition.Name, err)
+	}
+
+	if len(definition.Indexes) == 0 {
+		return nil
+	}
+	indexModels := make([]mongo.IndexModel, 0, len(definition.Indexes))
+	for _, index := range definition.Indexes {
+		indexOpts := options.Index()
+		if index.Name != "" {
+			indexOpts.SetName(index.Name)
+		}
+		if index.Unique {
+			indexOpts.SetUnique(true)
+		}
+		if index.Sparse {
+			indexOpts.SetSparse(true)
+		}
+		if index.ExpireAfterSeconds != nil {
+			indexOpts.SetExpireAfterSeconds(*index.ExpireAfterSeconds)
+		}
+		if index.PartialFilterExpression != nil {
+			indexOpts.SetPartialFilterExpression(index.PartialFilterExpression)
+		}
+		indexModels = append(indexModels, mongo.IndexModel{Keys: index.Keys, Options: indexOpts})
+	}
+	if _, err := db.Collection(definition.Name).Indexes().CreateMany(ctx, indexModels); err != nil {
+		return fmt.Errorf("create indexes on collection %q: %w", definition.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package integrationtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// mongoProfileCollection is a name of the collection where MongoDB stores profiler output.
+	mongoProfileCollection = "system.profile"
+	// mongoProfileCollectionSize is a size of the capped profile collection, which is 1MB by default.
+	mongoProfileCollectionSize = 64 << 20
+	// mongoCollScanPlan is a plan summary reported by MongoDB for full collection scans.
+	mongoCollScanPlan = "COLLSCAN"
+)
+
+// MongoOperation describes a single operation captured by the MongoDB profiler.
+type MongoOperation struct {
+	// Op is an operation type as reported by the profiler, e.g. "query", "insert", "update", "remove", "command", "getmore".
+	Op string
+	// Command is a name of the executed command, e.g. "find", "aggregate", "insert".
+	Command string
+	// Collection is a name of the collection the operation was executed against.
+	Collection string
+	// PlanSummary is a summary of the query plan, e.g. "IXSCAN { name: 1 }" or "COLLSCAN".
+	// It is empty for operations that don't use a query plan.
+	PlanSummary string
+}
+
+// UsedIndex reports whether the operation used an index.
+func (o MongoOperation) UsedIndex() bool {
+	return strings.Contains(o.PlanSummary, "IXSCAN") || strings.Contains(o.PlanSummary, "IDHACK")
+}
+
+// IsCollScan reports whether the operation performed a full collection scan.
+func (o MongoOperation) IsCollScan() bool {
+	return strings.Contains(o.PlanSummary, mongoCollScanPlan)
+}
+
+// String returns a short human-readable representation of the operation.
+func (o MongoOperation) String() string {
+	if o.PlanSummary == "" {
+		return fmt.Sprintf("%s (%s) on %q", o.Command, o.Op, o.Collection)
+	}
+	return fmt.Sprintf("%s (%s) on %q: %s", o.Command, o.Op, o.Collection, o.PlanSummary)
+}
+
+// mongoProfilingStates holds the profiling state per database, so that profiling is only disabled
+// when the last of the tests profiling the same database in parallel finishes.
+var mongoProfilingStates = struct {
+	sync.Mutex
+	states map[string]*mongoProfilingState
+}{states: map[string]*mongoProfilingState{}}
+
+// mongoProfilingState is the profiling state of a database shared by its profilers.
+type mongoProfilingState struct {
+	// profilers is the number of started profilers.
+	profilers int
+	// prevLevel and prevSlowMS are the settings before profiling was started,
+	// restored when the last profiler stops.
+	prevLevel  int
+	prevSlowMS int
+}
+
+// mongoProfilerSeq makes application names of profilers started at the same time unique.
+var mongoProfilerSeq atomic.Int64
+
+// MongoProfiler captures operations executed against a single MongoDB database by the clients of a single test.
+// Only operations of the clients connected with ConnURL or Client are captured, since the profiler tells them
+// apart from the traffic of other tests by a unique application name.
+type MongoProfiler struct {
+	db      *mongo.Database
+	connURL string
+	appName string
+	// since is the server time the profiler was started or last reset at.
+	since time.Time
+}
+
+// StartProfiler enables the MongoDB profiler for all operations on the given database.
+// The code under test must connect with the profiler's ConnURL or Client for its operations to be captured.
+// Tests profiling the same database may run in parallel. The profiler is disabled when the last of them finishes.
+func (m *MongoDockerInstance) StartProfiler(t *testing.T, dbName string) *MongoProfiler {
+	t.Helper()
+	ctx := context.Background()
+	appName := fmt.Sprintf("profiler-%d-%d", time.Now().UnixNano(), mongoProfilerSeq.Add(1))
+	connURL, err := url.Parse(m.ConnURL)
+	require.NoError(t, err)
+	query := connURL.Query()
+	query.Set("appName", appName)
+	connURL.RawQuery = query.Encode()
+
+	profiler := &MongoProfiler{db: m.mongoClient.Database(dbName), connURL: connURL.String(), appName: appName}
+	key := m.ConnURL + "|" + dbName
+	mongoProfilingStates.Lock()
+	defer mongoProfilingStates.Unlock()
+	state := mongoProfilingStates.states[key]
+	if state == nil {
+		state = &mongoProfilingState{}
+		state.prevLevel, state.prevSlowMS, err = profiler.enable(ctx)
+		require.NoError(t, err)
+		mongoProfilingStates.states[key] = state
+	}
+	state.profilers++
+
+	t.Cleanup(func() {
+		mongoProfilingStates.Lock()
+		defer mongoProfilingStates.Unlock()
+		state.profilers--
+		if state.profilers > 0 {
+			return
+		}
+		delete(mongoProfilingStates.states, key)
+		if _, _, err := profiler.setProfiling(context.Background(), state.prevLevel, &state.prevSlowMS); err != nil {
+			t.Logf("failed to disable MongoDB profiler: %v", err)
+		}
+	})
+
+	profiler.Reset(t)
+	return profiler
+}
+
+// ConnURL returns a connection URL with the profiler's application name, which the code under test must connect with.
+func (p *MongoProfiler) ConnURL() string {
+	return p.connURL
+}
+
+// Client returns a new MongoDB client connected with ConnURL. The client is disconnected when the test finishes.
+func (p *MongoProfiler) Client(t *testing.T) *mongo.Client {
+	t.Helper()
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(p.connURL))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := client.Disconnect(ctx); err != nil {
+			t.Logf("failed to disconnect MongoDB client: %v", err)
+		}
+	})
+	return client
+}
+
+// Reset discards all operations captured so far, so that subsequent assertions only
+// see operations executed after this call.
+func (p *MongoProfiler) Reset(t *testing.T) {
+	t.Helper()
+	var resp struct {
+		LocalTime time.Time `bson:"localTime"`
+	}
+	require.NoError(t, p.db.RunCommand(context.Background(), bson.D{{Key: "isMaster", Value: 1}}).Decode(&resp))
+	p.since = resp.LocalTime
+	// profiler timestamps have millisecond precision, so the next operation must not happen in the same millisecond:
+	time.Sleep(time.Millisecond)
+}
+
+// Operations returns all operations captured since the profiler was started or last reset,
+// in the order they were executed.
+// It fails the test if some of them may have been evicted from the capped profile collection.
+func (p *MongoProfiler) Operations(t *testing.T) []MongoOperation {
+	t.Helper()
+	ctx := context.Background()
+	profile := p.db.Collection(mongoProfileCollection)
+
+	// system.profile is a capped collection, so the natural order is the order of execution:
+	var oldest struct {
+		TS time.Time `bson:"ts"`
+	}
+	err := profile.FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "$natural", Value: 1}})).Decode(&oldest)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		require.NoError(t, err)
+		require.Falsef(t, oldest.TS.After(p.since),
+			"MongoDB profiler entries since %s have been evicted from %q, the oldest one is at %s",
+			p.since, mongoProfileCollection, oldest.TS)
+	}
+
+	filter := bson.D{
+		{Key: "appName", Value: p.appName},
+		{Key: "ts", Value: bson.D{{Key: "$gt", Value: p.since}}},
+	}
+	cursor, err := profile.Find(ctx, filter)
+	require.NoError(t, err)
+	defer cursor.Close(ctx)
+
+	var operations []MongoOperation
+	for cursor.Next(ctx) {
+		var entry struct {
+			Op          string `bson:"op"`
+			NS          string `bson:"ns"`
+			Command     bson.D `bson:"command"`
+			PlanSummary string `bson:"planSummary"`
+		}
+		require.NoError(t, cursor.Decode(&entry))
+
+		operation := MongoOperation{
+			Op:          entry.Op,
+			Collection:  strings.TrimPrefix(entry.NS, p.db.Name()+"."),
+			PlanSummary: entry.PlanSummary,
+		}
+		if len(entry.Command) > 0 {
+			operation.Command = entry.Command[0].Key
+		}
+		operations = append(operations, operation)
+	}
+	require.NoError(t, cursor.Err())
+	return operations
+}
+
+// AssertMaxOperations asserts that no more than max operations were executed
+// since the profiler was started or last reset.
+func (p *MongoProfiler) AssertMaxOperations(t *testing.T, max int) bool {
+	t.Helper()
+	operations := p.Operations(t)
+	return assert.LessOrEqualf(t, len(operations), max, "too many MongoDB operations executed:\n%s", formatMongoOperations(operations))
+}
+
+// AssertNoCollScan asserts that none of the operations executed since the profiler
+// was started or last reset performed a full collection scan.
+func (p *MongoProfiler) AssertNoCollScan(t *testing.T) bool {
+	t.Helper()
+	var collScans []MongoOperation
+	for _, operation := range p.Operations(t) {
+		if operation.IsCollScan() {
+			collScans = append(collScans, operation)
+		}
+	}
+	return assert.Emptyf(t, collScans, "MongoDB operations performed a collection scan:\n%s", formatMongoOperations(collScans))
+}
+
+// enable recreates the profile collection with mongoProfileCollectionSize and captures all operations
+// on the database. It returns the profiling level and the slow operation threshold set before.
+func (p *MongoProfiler) enable(ctx context.Context) (prevLevel, prevSlowMS int, err error) {
+	// system.profile can only be dropped and created while the profiler is off:
+	prevLevel, prevSlowMS, err = p.setProfiling(ctx, 0, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := p.db.Collection(mongoProfileCollection).Drop(ctx); err != nil {
+		return 0, 0, fmt.Errorf("drop MongoDB profile collection: %w", err)
+	}
+	createOpts := options.CreateCollection().SetCapped(true).SetSizeInBytes(mongoProfileCollectionSize)
+	if err := p.db.CreateCollection(ctx, mongoProfileCollection, createOpts); err != nil {
+		return 0, 0, fmt.Errorf("create MongoDB profile collection: %w", err)
+	}
+	// all operations are logged as slow, so that the profiler captures them:
+	allSlowMS := 0
+	if _, _, err := p.setProfiling(ctx, 2, &allSlowMS); err != nil {
+		return 0, 0, err
+	}
+	return prevLevel, prevSlowMS, nil
+}
+
+// setProfiling sets the profiling level of the database: 0 is off, 2 captures all operations.
+// The slow operation threshold is server-wide, so it is only changed when slowMS is not nil.
+// It returns the profiling level and the slow operation threshold set before.
+func (p *MongoProfiler) setProfiling(ctx context.Context, level int, slowMS *int) (prevLevel, prevSlowMS int, err error) {
+	command := bson.D{{Key: "profile", Value: level}}
+	if slowMS != nil {
+		command = append(command, bson.E{Key: "slowms", Value: *slowMS})
+	}
+	var resp struct {
+		Was    int `bson:"was"`
+		SlowMS int `bson:"slowms"`
+	}
+	if err := p.db.RunCommand(ctx, command).Decode(&resp); err != nil {
+		return 0, 0, fmt.Errorf("set MongoDB profiling level to %d: %w", level, err)
+	}
+	return resp.Was, resp.SlowMS, nil
+}
+
+func formatMongoOperations(operations []MongoOperation) string {
+	var sb strings.Builder
+	for _, operation := range operations {
+		sb.WriteString("  ")
+		sb.WriteString(operation.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,216 @@
+package integrationtesting
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	stdlog "log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/go-sql-driver/mysql"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// mySQLImageName specifies the default Docker image name for MySQL.
+	mySQLImageName = "mysql:8.0.35"
+)
+
+// MySQLOption configures the MySQL test container started by RunMySQLDockerContainer.
+type MySQLOption func(*mySQLConfig)
+
+// mySQLConfig holds MySQL test container settings.
+type mySQLConfig struct {
+	image string
+}
+
+// WithMySQLImage overrides the default MySQL Docker image.
+// MariaDB images, e.g. "mariadb:11.2", are supported as well.
+func WithMySQLImage(image string) MySQLOption {
+	return func(cfg *mySQLConfig) {
+		cfg.image = image
+	}
+}
+
+// RunMySQLDockerContainer creates a new MySQL (or MariaDB) test container and initializes the application repositories.
+// The container is considered started once an authenticated ping to the DB succeeds.
+// It returns a cleanup function that must be called to terminate the container.
+func RunMySQLDockerContainer(opts ...MySQLOption) (MySQLDockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		mySQLInternalPort = "3306"
+		userName          = "testuser"
+		userPass          = "testpassword"
+		dbName            = "integrationdb"
+	)
+
+	cfg := mySQLConfig{
+		image: mySQLImageName,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	connURLFn := func(host string, port nat.Port) string {
+		mySQLConfig := mysql.NewConfig()
+		mySQLConfig.User = userName
+		mySQLConfig.Passwd = userPass
+		mySQLConfig.Net = "tcp"
+		mySQLConfig.Addr = net.JoinHostPort(host, port.Port())
+		mySQLConfig.DBName = dbName
+		mySQLConfig.ParseTime = true
+		return mySQLConfig.FormatDSN()
+	}
+
+	mySQLPort := nat.Port(mySQLInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        cfg.image,
+			ExposedPorts: []string{mySQLPort.Port()},
+			Env: map[string]string{
+				// MariaDB images accept the same variables for compatibility:
+				"MYSQL_ROOT_PASSWORD": userPass,
+				"MYSQL_USER":          userName,
+				"MYSQL_PASSWORD":      userPass,
+				"MYSQL_DATABASE":      dbName,
+			},
+			WaitingFor: wait.ForSQL(mySQLPort, "mysql", connURLFn).WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true, // auto-start the container
+	}
+	mySQLContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return MySQLDockerInstance{}, func() {}, fmt.Errorf("mySQL container start: %w", err)
+	}
+
+	var db *sql.DB
+
+	// Test container cleanup function:
+	terminateFn := func() {
+		if db != nil {
+			if err := db.Close(); err != nil {
+				stdlog.Printf("Failed to close MySQL connection: %+v", err)
+			}
+		}
+		if err := mySQLContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("Failed to terminate MySQL test container: %+v", err)
+			return
+		}
+		stdlog.Println("MySQL test container terminated")
+	}
+
+	mySQLHostIP, err := mySQLContainer.Host(ctx)
+	if err != nil {
+		return MySQLDockerInstance{}, terminateFn, fmt.Errorf("map MySQL host: %w", err)
+	}
+
+	mySQLHostPort, err := mySQLContainer.MappedPort(ctx, mySQLPort)
+	if err != nil {
+		return MySQLDockerInstance{}, terminateFn, fmt.Errorf("map MySQL port: %w", err)
+	}
+
+	connURL := connURLFn(mySQLHostIP, mySQLHostPort)
+
+	// setup DB connection pool:
+	db, err = sql.Open("mysql", connURL)
+	if err != nil {
+		return MySQLDockerInstance{}, terminateFn, fmt.Errorf("failed to open MySQL connection: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return MySQLDockerInstance{}, terminateFn, fmt.Errorf("ping MySQL: %w", err)
+	}
+
+	instance := MySQLDockerInstance{
+		ConnURL:  connURL,
+		UserName: userName,
+		UserPass: userPass,
+		DbName:   dbName,
+		mySQLDB:  db,
+	}
+	stdlog.Printf("MySQL container started, running at: %q\n", connURL)
+	return instance, terminateFn, nil
+}
+
+// MySQLDockerInstance represents a running MySQL (or MariaDB) test container with settings.
+type MySQLDockerInstance struct {
+	// ConnURL is a fully constructed Go MySQL driver DSN with all resolved values, e.g. "testuser:testpassword@tcp(localhost:3306)/integrationdb?parseTime=true".
+	ConnURL string
+	// UserName is a username used for DB connection.
+	UserName string
+	// UserPass is a user password used for DB connection.
+	UserPass string
+	// DbName is a name of the DB.
+	DbName  string
+	mySQLDB *sql.DB
+}
+
+// DB returns a connection pool to the MySQL DB.
+func (m *MySQLDockerInstance) DB() *sql.DB {
+	return m.mySQLDB
+}
+
+// MustTruncateData truncates all data in the MySQL DB.
+// Foreign key checks are disabled while truncating, so tables can be truncated in any order.
+// Can be used after the tests to clean up all the user's data.
+//
+// It panics if the truncation fails.
+func (m *MySQLDockerInstance) MustTruncateData() {
+	ctx := context.Background()
+
+	// foreign key checks are disabled per session, so all statements must use the same connection:
+	conn, err := m.mySQLDB.Conn(ctx)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	tableNames := func() []string {
+		query := `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'`
+		rows, err := conn.QueryContext(ctx, query)
+		if err != nil {
+			panic(err)
+		}
+		defer rows.Close()
+
+		var tables []string
+		for rows.Next() {
+			var table string
+			err := rows.Scan(&table)
+			if err != nil {
+				panic(err)
+			}
+			tables = append(tables, table)
+		}
+		if err := rows.Err(); err != nil {
+			panic(err)
+		}
+		return tables
+	}()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		panic(err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1"); err != nil {
+			panic(err)
+		}
+	}()
+
+	for _, tableName := range tableNames {
+		query := "TRUNCATE TABLE " + quoteIdentifier(tableName)
+		_, err := conn.ExecContext(ctx, query)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// quoteIdentifier quotes the name of a MySQL or ClickHouse table or database with backticks,
+// doubling backticks within the name.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
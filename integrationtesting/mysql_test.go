@@ -0,0 +1,14 @@
+package integrationtesting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, "`orders`", quoteIdentifier("orders"))
+	assert.Equal(t, "`order items`", quoteIdentifier("order items"))
+	assert.Equal(t, "`a``b`", quoteIdentifier("a`b"))
+	assert.Equal(t, "```; DROP TABLE `` users`", quoteIdentifier("`; DROP TABLE ` users"))
+}
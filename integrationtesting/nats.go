@@ -0,0 +1,193 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// natsImageName specifies Docker image name for NATS.
+const natsImageName = "nats:2.10-alpine"
+
+// NATSDockerInstance is a config with NATS connection settings.
+type NATSDockerInstance struct {
+	// ConnURL is a NATS connection URL, e.g. "nats://localhost:4222".
+	ConnURL   string
+	natsConn  *nats.Conn
+	jetStream jetstream.JetStream
+}
+
+// Connection returns a NATS connection to the test container.
+func (n *NATSDockerInstance) Connection() *nats.Conn {
+	return n.natsConn
+}
+
+// JetStream returns a JetStream context bound to the NATS connection.
+func (n *NATSDockerInstance) JetStream() jetstream.JetStream {
+	return n.jetStream
+}
+
+// NATSStream describes a JetStream stream, along with its durable consumers, to be created with CreateStreams.
+type NATSStream struct {
+	// Config is a stream configuration, e.g. jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}}.
+	Config jetstream.StreamConfig
+	// Consumers are configurations of the stream consumers.
+	Consumers []jetstream.ConsumerConfig
+}
+
+// NATSOption configures the NATS test container started by RunNATSDockerContainer.
+type NATSOption func(*natsConfig)
+
+// natsConfig holds NATS test container settings.
+type natsConfig struct {
+	image string
+}
+
+// WithNATSImage overrides the default NATS Docker image.
+func WithNATSImage(image string) NATSOption {
+	return func(cfg *natsConfig) {
+		cfg.image = image
+	}
+}
+
+// RunNATSDockerContainer creates new NATS test container with JetStream enabled and initializes application repositories.
+// Returns cleanup function that must be called.
+func RunNATSDockerContainer(opts ...NATSOption) (NATSDockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		natsInternalPort = "4222"
+
+		natsConnectionURLTemplate = "nats://%s:%s"
+	)
+
+	cfg := natsConfig{
+		image: natsImageName,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	natsPort := nat.Port(natsInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        cfg.image,
+			ExposedPorts: []string{natsPort.Port()},
+			Cmd:          []string{"--jetstream"},
+			WaitingFor:   wait.ForLog("Server is ready"),
+		},
+		Started: true, // auto-start the container
+	}
+	natsContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return NATSDockerInstance{}, func() {}, fmt.Errorf("nats container start: %w", err)
+	}
+
+	var natsConn *nats.Conn
+
+	// Test container clean up function:
+	terminateFn := func() {
+		if natsConn != nil {
+			natsConn.Close()
+		}
+		if err := natsContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("failed to terminate NATS test container: %v", err)
+			return
+		}
+		stdlog.Println("NATS test container terminated")
+	}
+
+	natsHostIP, err := natsContainer.Host(ctx)
+	if err != nil {
+		return NATSDockerInstance{}, terminateFn, fmt.Errorf("map NATS host: %w", err)
+	}
+
+	natsHostPort, err := natsContainer.MappedPort(ctx, natsPort)
+	if err != nil {
+		return NATSDockerInstance{}, terminateFn, fmt.Errorf("map NATS port: %w", err)
+	}
+
+	natsURL := fmt.Sprintf(natsConnectionURLTemplate, natsHostIP, natsHostPort.Port())
+
+	// setup NATS connection:
+	natsConn, err = nats.Connect(natsURL)
+	if err != nil {
+		return NATSDockerInstance{}, terminateFn, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	jetStream, err := jetstream.New(natsConn)
+	if err != nil {
+		return NATSDockerInstance{}, terminateFn, fmt.Errorf("create JetStream context: %w", err)
+	}
+
+	instance := NATSDockerInstance{
+		ConnURL:   natsURL,
+		natsConn:  natsConn,
+		jetStream: jetStream,
+	}
+	stdlog.Printf("NATS container started, running at: %q\n", natsURL)
+	return instance, terminateFn, nil
+}
+
+// CreateStreams creates or updates the JetStream streams along with their consumers.
+func (n *NATSDockerInstance) CreateStreams(ctx context.Context, streams ...NATSStream) error {
+	for _, stream := range streams {
+		if _, err := n.jetStream.CreateOrUpdateStream(ctx, stream.Config); err != nil {
+			return fmt.Errorf("create JetStream stream %q: %w", stream.Config.Name, err)
+		}
+		for _, consumer := range stream.Consumers {
+			if _, err := n.jetStream.CreateOrUpdateConsumer(ctx, stream.Config.Name, consumer); err != nil {
+				name := consumer.Name
+				if name == "" {
+					name = consumer.Durable
+				}
+				return fmt.Errorf("create JetStream consumer %q of stream %q: %w", name, stream.Config.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MustCreateStreams is like CreateStreams, but panics if the streams can't be created.
+func (n *NATSDockerInstance) MustCreateStreams(streams ...NATSStream) {
+	if err := n.CreateStreams(context.Background(), streams...); err != nil {
+		panic(err)
+	}
+}
+
+// PurgeStreams deletes all messages from the JetStream streams; the streams and their consumers are kept.
+// When no stream names are given, all streams are purged.
+// Can be used after the tests to clean up all the user's data.
+func (n *NATSDockerInstance) PurgeStreams(ctx context.Context, streams ...string) error {
+	if len(streams) == 0 {
+		names := n.jetStream.StreamNames(ctx)
+		for name := range names.Name() {
+			streams = append(streams, name)
+		}
+		if err := names.Err(); err != nil {
+			return fmt.Errorf("list JetStream streams: %w", err)
+		}
+	}
+
+	for _, name := range streams {
+		stream, err := n.jetStream.Stream(ctx, name)
+		if err != nil {
+			return fmt.Errorf("get JetStream stream %q: %w", name, err)
+		}
+		if err := stream.Purge(ctx); err != nil {
+			return fmt.Errorf("purge JetStream stream %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// MustPurgeStreams is like PurgeStreams, but panics if the streams can't be purged.
+func (n *NATSDockerInstance) MustPurgeStreams(streams ...string) {
+	if err := n.PurgeStreams(context.Background(), streams...); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,67 @@
+package integrationtesting
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NATSTestSubscriber collects all messages published to a subject for assertions in tests.
+type NATSTestSubscriber struct {
+	subject   string
+	collector messageCollector[*nats.Msg]
+}
+
+// Subscribe starts collecting messages published to the subject, which may contain wildcards, e.g. "orders.>".
+// Only messages published after the subscription are collected; this includes messages stored by JetStream streams.
+// The subscription is stopped when the test finishes.
+func (n *NATSDockerInstance) Subscribe(t *testing.T, subject string) *NATSTestSubscriber {
+	t.Helper()
+	subscriber := &NATSTestSubscriber{subject: subject}
+	subscription, err := n.natsConn.Subscribe(subject, subscriber.collector.add)
+	require.NoError(t, err)
+	// make sure the server has registered the subscription before anything is published:
+	require.NoError(t, n.natsConn.Flush())
+
+	t.Cleanup(func() {
+		if err := subscription.Unsubscribe(); err != nil {
+			t.Logf("unsubscribe from NATS subject %q: %v", subject, err)
+		}
+	})
+	return subscriber
+}
+
+// Messages returns a copy of all messages collected so far.
+func (s *NATSTestSubscriber) Messages() []*nats.Msg {
+	return s.collector.all()
+}
+
+// WaitForMessage waits until a message matching the predicate is collected and returns it.
+// It returns false if no such message arrives within the timeout.
+func (s *NATSTestSubscriber) WaitForMessage(timeout time.Duration, match func(*nats.Msg) bool) (*nats.Msg, bool) {
+	return s.collector.wait(timeout, match)
+}
+
+// AssertEventuallyMessageOnSubject asserts that a message published to the exact subject, e.g. "orders.created",
+// arrives within the timeout, and returns it.
+func (s *NATSTestSubscriber) AssertEventuallyMessageOnSubject(t *testing.T, subject string, timeout time.Duration) (*nats.Msg, bool) {
+	t.Helper()
+	message, ok := s.WaitForMessage(timeout, func(message *nats.Msg) bool {
+		return message.Subject == subject
+	})
+	if !ok {
+		assert.Failf(t, "message not received", "no message on subject %q arrived to subscription %q within %s, %s",
+			subject, s.subject, timeout, s.collector.status())
+	}
+	return message, ok
+}
+
+// AssertEventuallyMessageCount asserts that at least count messages arrive to the subscription within the timeout.
+func (s *NATSTestSubscriber) AssertEventuallyMessageCount(t *testing.T, count int, timeout time.Duration) bool {
+	t.Helper()
+	return s.collector.assertCount(t, count, timeout, fmt.Sprintf("subscription %q", s.subject), s.collector.status)
+}
@@ -0,0 +1,46 @@
+package integrationtesting
+
+const (
+	// openSearchImageName specifies Docker image name for OpenSearch.
+	openSearchImageName = "opensearchproject/opensearch:2.12.0"
+
+	// openSearchSuperUserName is a name of the built-in OpenSearch superuser.
+	openSearchSuperUserName = "admin"
+	// openSearchCACertPath is a path to the CA certificate of OpenSearch demo security configuration.
+	openSearchCACertPath = "/usr/share/opensearch/config/root-ca.pem"
+)
+
+// openSearchEngine describes OpenSearch.
+var openSearchEngine = searchEngine{
+	name:          "OpenSearch",
+	image:         openSearchImageName,
+	secureImage:   openSearchImageName,
+	superUserName: openSearchSuperUserName,
+	caCertPath:    openSearchCACertPath,
+	// the demo node certificate is issued for this host name only:
+	tlsServerName:    "node-0.example.com",
+	javaOptsEnv:      "OPENSEARCH_JAVA_OPTS",
+	pluginInstallCmd: "bin/opensearch-plugin install --batch",
+	entrypoint:       "./opensearch-docker-entrypoint.sh opensearch",
+	env: func(cfg elasticConfig) map[string]string {
+		env := map[string]string{}
+		if cfg.security {
+			env["OPENSEARCH_INITIAL_ADMIN_PASSWORD"] = cfg.password
+		} else {
+			env["DISABLE_SECURITY_PLUGIN"] = "true"
+			env["DISABLE_INSTALL_DEMO_CONFIG"] = "true"
+		}
+		return env
+	},
+}
+
+// RunOpenSearchDockerContainer creates new OpenSearch test container and initializes application repositories.
+// The container is considered started once the cluster health is at least yellow.
+// The security plugin is disabled unless WithElasticSecurity option is given.
+// Returns cleanup function that must be called.
+//
+// The returned ElasticDockerInstance supports the same helpers as the one returned by RunElasticsearchDockerContainer,
+// so the same tests can be run against both engines.
+func RunOpenSearchDockerContainer(opts ...ElasticOption) (ElasticDockerInstance, func(), error) {
+	return runSearchDockerContainer(openSearchEngine, opts...)
+}
@@ -0,0 +1,80 @@
+package integrationtesting
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RabbitMQTestConsumer collects all messages delivered to a queue for assertions in tests.
+type RabbitMQTestConsumer struct {
+	queue     string
+	collector messageCollector[amqp.Delivery]
+}
+
+// Consume starts collecting messages from the queue. Messages are acknowledged automatically,
+// so they are taken away from other consumers of the queue.
+// The consumer is stopped when the test finishes.
+func (r *RabbitMQDockerInstance) Consume(t *testing.T, queue string) *RabbitMQTestConsumer {
+	t.Helper()
+	channel, err := r.amqpConn.Channel()
+	require.NoError(t, err)
+
+	consumerTag := fmt.Sprintf("test-consumer-%s-%d", queue, time.Now().UnixNano())
+	deliveries, err := channel.Consume(queue, consumerTag, true, false, false, false, nil)
+	require.NoError(t, err)
+
+	consumer := &RabbitMQTestConsumer{queue: queue}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for delivery := range deliveries {
+			consumer.collector.add(delivery)
+		}
+	}()
+	t.Cleanup(func() {
+		if err := channel.Cancel(consumerTag, false); err != nil {
+			t.Logf("cancel RabbitMQ consumer of queue %q: %v", queue, err)
+		}
+		<-done
+		if err := channel.Close(); err != nil {
+			t.Logf("close RabbitMQ channel: %v", err)
+		}
+	})
+	return consumer
+}
+
+// Messages returns a copy of all messages collected so far.
+func (c *RabbitMQTestConsumer) Messages() []amqp.Delivery {
+	return c.collector.all()
+}
+
+// WaitForMessage waits until a message matching the predicate is collected and returns it.
+// It returns false if no such message arrives within the timeout.
+func (c *RabbitMQTestConsumer) WaitForMessage(timeout time.Duration, match func(amqp.Delivery) bool) (amqp.Delivery, bool) {
+	return c.collector.wait(timeout, match)
+}
+
+// AssertEventuallyMessageWithRoutingKey asserts that a message published with the routing key
+// arrives to the queue within the timeout, and returns it.
+func (c *RabbitMQTestConsumer) AssertEventuallyMessageWithRoutingKey(t *testing.T, routingKey string, timeout time.Duration) (amqp.Delivery, bool) {
+	t.Helper()
+	message, ok := c.WaitForMessage(timeout, func(message amqp.Delivery) bool {
+		return message.RoutingKey == routingKey
+	})
+	if !ok {
+		assert.Failf(t, "message not received", "no message with routing key %q arrived to queue %q within %s, %s",
+			routingKey, c.queue, timeout, c.collector.status())
+	}
+	return message, ok
+}
+
+// AssertEventuallyMessageCount asserts that at least count messages arrive to the queue within the timeout.
+func (c *RabbitMQTestConsumer) AssertEventuallyMessageCount(t *testing.T, count int, timeout time.Duration) bool {
+	t.Helper()
+	return c.collector.assertCount(t, count, timeout, fmt.Sprintf("queue %q", c.queue), c.collector.status)
+}
@@ -0,0 +1,211 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// redisImageName specifies Docker image name for Redis.
+	redisImageName = "redis:7.2-alpine"
+
+	// redisDatabases specifies the number of logical databases in Redis: 0 is the default one,
+	// the rest can be allocated to tests with AllocateDB.
+	redisDatabases = 16
+)
+
+// RedisDockerInstance is a config with Redis connection settings.
+type RedisDockerInstance struct {
+	// ConnURL is a connection URL of the default logical database, e.g. "redis://:pass@localhost:6379/0".
+	ConnURL string
+	// UserPass is a password of the default user; it is empty unless set with WithRedisPassword.
+	UserPass    string
+	redisClient *redis.Client
+	// freeDBs is a pool of logical databases which can be allocated to tests.
+	freeDBs chan int
+}
+
+// Client returns a Redis client connected to the default logical database.
+func (r *RedisDockerInstance) Client() *redis.Client {
+	return r.redisClient
+}
+
+// MustFlushDB deletes all keys in the default logical database.
+// Can be used after the tests to clean up all the user's data.
+//
+// It panics if the flush fails.
+func (r *RedisDockerInstance) MustFlushDB() {
+	if err := r.redisClient.FlushDB(context.Background()).Err(); err != nil {
+		panic(err)
+	}
+}
+
+// AllocateDB allocates one of the logical databases 1-15 to the test, so that parallel tests don't collide,
+// and returns a client connected to it. The database is flushed and released when the test finishes.
+// It blocks until a database becomes free.
+func (r *RedisDockerInstance) AllocateDB(t *testing.T) *redis.Client {
+	t.Helper()
+	db := <-r.freeDBs
+
+	options := *r.redisClient.Options()
+	options.DB = db
+	client := redis.NewClient(&options)
+	t.Cleanup(func() {
+		defer func() { r.freeDBs <- db }()
+		if err := client.FlushDB(context.Background()).Err(); err != nil {
+			t.Errorf("flush Redis logical database %d: %v", db, err)
+		}
+		if err := client.Close(); err != nil {
+			t.Logf("close Redis client: %v", err)
+		}
+	})
+
+	require.NoError(t, client.Ping(context.Background()).Err())
+	return client
+}
+
+// RedisOption configures the Redis test container started by RunRedisDockerContainer.
+type RedisOption func(*redisConfig)
+
+// redisConfig holds Redis test container settings.
+type redisConfig struct {
+	image    string
+	userPass string
+	// args are additional "redis-server" arguments.
+	args []string
+}
+
+// WithRedisImage overrides the default Redis Docker image, e.g. "redis:6.2".
+func WithRedisImage(image string) RedisOption {
+	return func(cfg *redisConfig) {
+		cfg.image = image
+	}
+}
+
+// WithRedisPassword requires clients to authenticate as the default user with the given password.
+func WithRedisPassword(userPass string) RedisOption {
+	return func(cfg *redisConfig) {
+		cfg.userPass = userPass
+		cfg.args = append(cfg.args, "--requirepass", userPass)
+	}
+}
+
+// WithRedisUser creates an ACL user with the given password and ACL rules, e.g. "~cache:*", "+get", "+set".
+// When no rules are given, the user has access to all keys, channels and commands.
+func WithRedisUser(userName, userPass string, rules ...string) RedisOption {
+	return func(cfg *redisConfig) {
+		if len(rules) == 0 {
+			rules = []string{"~*", "&*", "+@all"}
+		}
+		cfg.args = append(cfg.args, "--user", userName, "on", ">"+userPass)
+		cfg.args = append(cfg.args, rules...)
+	}
+}
+
+// WithRedisConfig overrides a configuration directive, as if it was specified in "redis.conf",
+// e.g. WithRedisConfig("maxmemory-policy", "allkeys-lru").
+func WithRedisConfig(directive string, values ...string) RedisOption {
+	return func(cfg *redisConfig) {
+		cfg.args = append(cfg.args, "--"+strings.TrimPrefix(directive, "--"))
+		cfg.args = append(cfg.args, values...)
+	}
+}
+
+// RunRedisDockerContainer creates new Redis test container and initializes application repositories.
+// Returns cleanup function that must be called.
+func RunRedisDockerContainer(opts ...RedisOption) (RedisDockerInstance, func(), error) {
+	ctx := context.Background()
+	const (
+		redisInternalPort = "6379"
+
+		redisConnectionURLTemplate = "redis://%s%s:%s/0"
+	)
+
+	cfg := redisConfig{
+		image: redisImageName,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	redisPort := nat.Port(redisInternalPort + "/tcp")
+	containerRequest := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        cfg.image,
+			ExposedPorts: []string{redisPort.Port()},
+			Cmd:          append([]string{"redis-server", "--databases", fmt.Sprint(redisDatabases)}, cfg.args...),
+			WaitingFor:   wait.ForLog("Ready to accept connections"),
+		},
+		Started: true, // auto-start the container
+	}
+	redisContainer, err := testcontainers.GenericContainer(ctx, containerRequest)
+	if err != nil {
+		return RedisDockerInstance{}, func() {}, fmt.Errorf("redis container start: %w", err)
+	}
+
+	var redisClient *redis.Client
+
+	// Test container clean up function:
+	terminateFn := func() {
+		if redisClient != nil {
+			if err := redisClient.Close(); err != nil {
+				stdlog.Printf("failed to close Redis client: %v", err)
+			}
+		}
+		if err := redisContainer.Terminate(ctx); err != nil {
+			stdlog.Printf("failed to terminate Redis test container: %v", err)
+			return
+		}
+		stdlog.Println("Redis test container terminated")
+	}
+
+	redisHostIP, err := redisContainer.Host(ctx)
+	if err != nil {
+		return RedisDockerInstance{}, terminateFn, fmt.Errorf("map Redis host: %w", err)
+	}
+
+	redisHostPort, err := redisContainer.MappedPort(ctx, redisPort)
+	if err != nil {
+		return RedisDockerInstance{}, terminateFn, fmt.Errorf("map Redis port: %w", err)
+	}
+
+	var userInfo string
+	if cfg.userPass != "" {
+		userInfo = url.UserPassword("", cfg.userPass).String() + "@"
+	}
+	redisURL := fmt.Sprintf(redisConnectionURLTemplate, userInfo, redisHostIP, redisHostPort.Port())
+
+	// setup Redis client:
+	redisOptions, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return RedisDockerInstance{}, terminateFn, fmt.Errorf("parse Redis URL: %w", err)
+	}
+	redisClient = redis.NewClient(redisOptions)
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		return RedisDockerInstance{}, terminateFn, fmt.Errorf("ping Redis: %w", err)
+	}
+
+	freeDBs := make(chan int, redisDatabases-1)
+	for db := 1; db < redisDatabases; db++ {
+		freeDBs <- db
+	}
+
+	instance := RedisDockerInstance{
+		ConnURL:     redisURL,
+		UserPass:    cfg.userPass,
+		redisClient: redisClient,
+		freeDBs:     freeDBs,
+	}
+	stdlog.Printf("Redis container started, running at: %q\n", redisURL)
+	return instance, terminateFn, nil
+}
@@ -0,0 +1,184 @@
+package integrationtesting
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// testCertificateValidity specifies how long generated test certificates are valid.
+	testCertificateValidity = 24 * time.Hour
+
+	testCAFileName         = "ca.pem"
+	testServerCertFileName = "server.pem"
+	testClientCertFileName = "client.pem"
+)
+
+// testCertificates is a set of throwaway certificates generated for a single test container.
+type testCertificates struct {
+	// Dir is a directory where all PEM files are stored.
+	Dir string
+	// CAFile is a path to the PEM-encoded CA certificate.
+	CAFile string
+	// ServerFile is a path to the PEM-encoded server certificate followed by its private key.
+	ServerFile string
+	// ClientFile is a path to the PEM-encoded client certificate followed by its private key.
+	ClientFile string
+	// ClientSubject is an RFC 2253 subject of the client certificate.
+	ClientSubject string
+	// ClientTLSConfig is a TLS config trusting the CA and presenting the client certificate.
+	ClientTLSConfig *tls.Config
+}
+
+// Remove deletes all generated certificate files.
+func (c testCertificates) Remove() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// generateTestCertificates creates a CA, a server certificate valid for the given hosts,
+// and a client certificate signed by that CA, and writes them into a new temporary directory.
+func generateTestCertificates(serverSubject, clientSubject pkix.Name, hosts ...string) (testCertificates, error) {
+	dir, err := os.MkdirTemp("", "testcontainer-certs-")
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("create certificates dir: %w", err)
+	}
+	certs, err := writeTestCertificates(dir, serverSubject, clientSubject, hosts)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return testCertificates{}, err
+	}
+	return certs, nil
+}
+
+func writeTestCertificates(dir string, serverSubject, clientSubject pkix.Name, hosts []string) (testCertificates, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("generate CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "testcontainers CA", Organization: []string{"testcontainers"}},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caCert, caDER, err := signTestCertificate(caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("create CA certificate: %w", err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("generate server key: %w", err)
+	}
+	serverTemplate := &x509.Certificate{
+		Subject:     serverSubject,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	_, serverDER, err := signTestCertificate(serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("create server certificate: %w", err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("generate client key: %w", err)
+	}
+	clientTemplate := &x509.Certificate{
+		Subject:     clientSubject,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientCert, clientDER, err := signTestCertificate(clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("create client certificate: %w", err)
+	}
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	serverPEM, err := encodeCertificateWithKey(serverDER, serverKey)
+	if err != nil {
+		return testCertificates{}, err
+	}
+	clientPEM, err := encodeCertificateWithKey(clientDER, clientKey)
+	if err != nil {
+		return testCertificates{}, err
+	}
+
+	certs := testCertificates{
+		Dir:           dir,
+		CAFile:        filepath.Join(dir, testCAFileName),
+		ServerFile:    filepath.Join(dir, testServerCertFileName),
+		ClientFile:    filepath.Join(dir, testClientCertFileName),
+		ClientSubject: clientCert.Subject.String(),
+	}
+	for path, content := range map[string][]byte{
+		certs.CAFile:     caPEM,
+		certs.ServerFile: serverPEM,
+		certs.ClientFile: clientPEM,
+	} {
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return testCertificates{}, fmt.Errorf("write %q: %w", path, err)
+		}
+	}
+
+	clientKeyPair, err := tls.X509KeyPair(clientPEM, clientPEM)
+	if err != nil {
+		return testCertificates{}, fmt.Errorf("load client key pair: %w", err)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(caCert)
+	certs.ClientTLSConfig = &tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: []tls.Certificate{clientKeyPair},
+		MinVersion:   tls.VersionTLS12,
+	}
+	return certs, nil
+}
+
+func signTestCertificate(template, parent *x509.Certificate, publicKey *ecdsa.PublicKey, signerKey *ecdsa.PrivateKey) (*x509.Certificate, []byte, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate serial number: %w", err)
+	}
+	template.SerialNumber = serialNumber
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(testCertificateValidity)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, der, nil
+}
+
+func encodeCertificateWithKey(certDER []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return append(certPEM, keyPEM...), nil
+}
@@ -0,0 +1,120 @@
+package integrationtesting
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readTestCertificate parses the first certificate from the PEM file.
+func readTestCertificate(t *testing.T, path string) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block, "no PEM block in %q", path)
+	require.Equal(t, "CERTIFICATE", block.Type)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestGenerateTestCertificates(t *testing.T) {
+	certs, err := generateTestCertificates(
+		pkix.Name{CommonName: "localhost", Organization: []string{"server"}},
+		pkix.Name{CommonName: "testclient", OrganizationalUnit: []string{"clients"}, Organization: []string{"testcontainers"}},
+		"localhost", "127.0.0.1", "docker.internal",
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, certs.Remove()) })
+
+	caCert := readTestCertificate(t, certs.CAFile)
+	serverCert := readTestCertificate(t, certs.ServerFile)
+	clientCert := readTestCertificate(t, certs.ClientFile)
+
+	assert.True(t, caCert.IsCA)
+	assert.Equal(t, []string{"localhost", "docker.internal"}, serverCert.DNSNames)
+	require.Len(t, serverCert.IPAddresses, 1)
+	assert.True(t, serverCert.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+	assert.Equal(t, "CN=testclient,OU=clients,O=testcontainers", certs.ClientSubject)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	for _, host := range []string{"localhost", "127.0.0.1", "docker.internal"} {
+		_, err := serverCert.Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		assert.NoError(t, err, "server certificate for %q", host)
+	}
+	_, err = serverCert.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots})
+	assert.Error(t, err, "server certificate must not be valid for other hosts")
+
+	_, err = clientCert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.NoError(t, err, "client certificate")
+}
+
+func TestGenerateTestCertificatesMutualTLS(t *testing.T) {
+	certs, err := generateTestCertificates(pkix.Name{CommonName: "localhost"}, pkix.Name{CommonName: "testclient"}, "127.0.0.1")
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, certs.Remove()) })
+
+	serverKeyPair, err := tls.LoadX509KeyPair(certs.ServerFile, certs.ServerFile)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	caPEM, err := os.ReadFile(certs.CAFile)
+	require.NoError(t, err)
+	require.True(t, clientCAs.AppendCertsFromPEM(caPEM))
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverKeyPair},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	peerSubject := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			peerSubject <- err.Error()
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			peerSubject <- err.Error()
+			return
+		}
+		peerSubject <- tlsConn.ConnectionState().PeerCertificates[0].Subject.String()
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), certs.ClientTLSConfig)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	assert.Equal(t, certs.ClientSubject, <-peerSubject)
+}
+
+func TestTestCertificatesRemove(t *testing.T) {
+	certs, err := generateTestCertificates(pkix.Name{CommonName: "localhost"}, pkix.Name{CommonName: "testclient"}, "localhost")
+	require.NoError(t, err)
+
+	require.NoError(t, certs.Remove())
+
+	_, err = os.Stat(certs.Dir)
+	assert.True(t, os.IsNotExist(err))
+}
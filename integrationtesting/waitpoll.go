@@ -0,0 +1,86 @@
+package integrationtesting
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// pollingStrategy waits until a readiness check against the mapped port succeeds, polling it at the interval.
+//
+// Unlike waiting for the listening port, it succeeds only when the service is able to serve requests.
+// If the service doesn't become ready in time, the last check error is included in the error.
+type pollingStrategy struct {
+	port     nat.Port
+	timeout  time.Duration
+	interval time.Duration
+	// name describes the awaited service in errors, e.g. "cluster health".
+	name string
+	// check returns nil once the service listening at the "host:port" address is ready.
+	check func(ctx context.Context, hostPort string) error
+}
+
+// forPolling creates a wait strategy polling the check for a service listening on the given port.
+func forPolling(port nat.Port, timeout, interval time.Duration, name string, check func(ctx context.Context, hostPort string) error) *pollingStrategy {
+	return &pollingStrategy{
+		port:     port,
+		timeout:  timeout,
+		interval: interval,
+		name:     name,
+		check:    check,
+	}
+}
+
+// Timeout implements wait.StrategyTimeout.
+func (s *pollingStrategy) Timeout() *time.Duration {
+	return &s.timeout
+}
+
+// WaitUntilReady implements wait.Strategy.
+func (s *pollingStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s is not ready after %s, last error: %v", s.name, s.timeout, lastErr)
+		case <-time.After(s.interval):
+		}
+
+		state, err := target.State(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !state.Running {
+			return fmt.Errorf("container is not running (status %q, exit code %d), last error: %v", state.Status, state.ExitCode, lastErr)
+		}
+
+		host, err := target.Host(ctx)
+		if err != nil {
+			lastErr = fmt.Errorf("map host: %w", err)
+			continue
+		}
+		mappedPort, err := target.MappedPort(ctx, s.port)
+		if err != nil {
+			lastErr = fmt.Errorf("map port: %w", err)
+			continue
+		}
+
+		lastErr = s.check(ctx, net.JoinHostPort(host, mappedPort.Port()))
+		if lastErr == nil {
+			return nil
+		}
+	}
+}
+
+var (
+	_ wait.Strategy        = &pollingStrategy{}
+	_ wait.StrategyTimeout = &pollingStrategy{}
+)
@@ -0,0 +1,36 @@
+package stdapproachwithsubtests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestCassandraIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	cassandra, cleanupFn, err := integrationtesting.RunCassandraDockerContainer()
+	t.Cleanup(cleanupFn) // runs after the parallel subtests, unlike defer
+	require.NoError(t, err)
+
+	// every parallel subtest gets its own keyspace with the schema applied, so the same rows don't collide:
+	for _, name := range []string{"TestExample1", "TestExample2"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, session := cassandra.AllocateKeyspace(t, "testdata/cassandra")
+
+			require.NoError(t, session.Query("INSERT INTO users (id, email) VALUES (?, ?)", "1", name+"@example.com").Exec())
+
+			var email string
+			require.NoError(t, session.Query("SELECT email FROM users WHERE id = ?", "1").Scan(&email))
+			assert.Equal(t, name+"@example.com", email)
+		})
+	}
+}
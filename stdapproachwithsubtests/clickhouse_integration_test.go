@@ -0,0 +1,51 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestClickHouseIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	clickHouse, cleanupFn, err := integrationtesting.RunClickHouseDockerContainer()
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	clickHouse.MustApplyDDLFiles("testdata/clickhouse")
+
+	ctx := context.Background()
+	countEvents := func(t *testing.T, eventType string) uint64 {
+		var events uint64
+		err := clickHouse.DB().QueryRowContext(ctx,
+			"SELECT sum(events) FROM events_by_type WHERE event_type = ?", eventType).Scan(&events)
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer clickHouse.MustTruncateData()
+
+		_, err := clickHouse.DB().ExecContext(ctx, `INSERT INTO events (event_time, user_id, event_type)
+VALUES (now(), 1, 'click'), (now(), 2, 'click'), (now(), 1, 'view')`)
+		require.NoError(t, err)
+
+		// the materialized view aggregates the inserted rows:
+		assert.Equal(t, uint64(2), countEvents(t, "click"))
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer clickHouse.MustTruncateData()
+
+		// the target table of the view is truncated, while the view itself is skipped:
+		assert.Equal(t, uint64(0), countEvents(t, "click"))
+	})
+}
@@ -0,0 +1,61 @@
+package stdapproachwithsubtests
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+)
+
+// TestHTTPStubIntegrationTest doesn't need Docker, since the stub server runs in the test process.
+// Containers started with integrationtesting.AddDockerHostGateway reach it at ContainerURL,
+// see integrationtesting.WithHTTPStubListenAddress.
+func TestHTTPStubIntegrationTest(t *testing.T) {
+	stub, cleanupFn, err := integrationtesting.RunHTTPStubServer()
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer stub.Reset()
+		stub.Stub(integrationtesting.HTTPStub{
+			Request: integrationtesting.HTTPStubMatcher{Method: http.MethodPost, Path: "/v1/payments", BodyJSON: `{"amount": 100}`},
+			Response: integrationtesting.HTTPStubResponse{
+				Status:  http.StatusCreated,
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    `{"id": "pay_1"}`,
+			},
+		})
+
+		resp, err := http.Post(stub.URL+"/v1/payments", "application/json", strings.NewReader(`{"amount":100}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.JSONEq(t, `{"id": "pay_1"}`, string(body))
+		stub.AssertReceived(t, integrationtesting.HTTPStubMatcher{Method: http.MethodPost, Path: "/v1/payments"}, 1)
+		stub.AssertNoUnmatchedRequests(t)
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer stub.Reset()
+		stub.Stub(integrationtesting.HTTPStub{
+			Request:  integrationtesting.HTTPStubMatcher{Method: http.MethodGet, Path: "/v1/payments/*"},
+			Response: integrationtesting.HTTPStubResponse{Status: http.StatusServiceUnavailable},
+		})
+
+		// stubs and requests of the previous test are reset:
+		resp, err := http.Get(stub.URL + "/v1/payments/pay_1")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		stub.AssertReceived(t, integrationtesting.HTTPStubMatcher{}, 1)
+	})
+}
@@ -0,0 +1,40 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestKafkaIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	kafkaInstance, cleanupFn, err := integrationtesting.RunKafkaDockerContainer()
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	kafkaInstance.MustCreateTopics(integrationtesting.KafkaTopic{Name: "orders", Partitions: 3})
+
+	t.Run("TestExample1", func(t *testing.T) {
+		consumer := kafkaInstance.Consume(t, "orders")
+
+		writer := &kafka.Writer{Addr: kafka.TCP(kafkaInstance.Brokers...), Topic: "orders", Balancer: &kafka.Hash{}}
+		defer writer.Close()
+		err := writer.WriteMessages(context.Background(),
+			kafka.Message{Key: []byte("order-1"), Value: []byte(`{"status":"created"}`)},
+			kafka.Message{Key: []byte("order-2"), Value: []byte(`{"status":"created"}`)},
+		)
+		require.NoError(t, err)
+
+		consumer.AssertEventuallyMessageWithKey(t, "order-2", 10*time.Second)
+		consumer.AssertEventuallyMessageCount(t, 2, 10*time.Second)
+	})
+}
@@ -0,0 +1,52 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestMailpitIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	mailpit, cleanupFn, err := integrationtesting.RunMailpitDockerContainer()
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	smtpAddr := fmt.Sprintf("%s:%d", mailpit.SMTPHost, mailpit.SMTPPort)
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer mailpit.MustDeleteMessages()
+
+		message := "To: alice@example.com\r\n" +
+			"Subject: Welcome!\r\n" +
+			"\r\n" +
+			"Hello, Alice!\r\n"
+		err := smtp.SendMail(smtpAddr, nil, "noreply@example.com", []string{"alice@example.com"}, []byte(message))
+		require.NoError(t, err)
+
+		email, ok := mailpit.AssertEventuallyEmail(t, "alice@example.com", "Welcome!", 5*time.Second)
+		if ok {
+			assert.Contains(t, email.Text, "Hello, Alice!")
+		}
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer mailpit.MustDeleteMessages()
+
+		// emails of the previous test are deleted:
+		messages, err := mailpit.Messages(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+}
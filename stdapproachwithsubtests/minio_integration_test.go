@@ -0,0 +1,57 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestMinIOIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	minIO, cleanupFn, err := integrationtesting.RunMinIODockerContainer(
+		integrationtesting.WithMinIOBuckets(integrationtesting.MinIOBucket{Name: "invoices", Versioning: true}),
+	)
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer minIO.MustEmptyBuckets()
+
+		const content = "invoice #1"
+		_, err := minIO.Client().PutObject(ctx, "invoices", "2023/1.txt", strings.NewReader(content), int64(len(content)),
+			minio.PutObjectOptions{ContentType: "text/plain"})
+		require.NoError(t, err)
+
+		object, err := minIO.Client().GetObject(ctx, "invoices", "2023/1.txt", minio.GetObjectOptions{})
+		require.NoError(t, err)
+		defer object.Close()
+		data, err := io.ReadAll(object)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer minIO.MustEmptyBuckets()
+
+		// all object versions of the previous test are deleted:
+		var objects []minio.ObjectInfo
+		for object := range minIO.Client().ListObjects(ctx, "invoices", minio.ListObjectsOptions{Recursive: true, WithVersions: true}) {
+			require.NoError(t, object.Err)
+			objects = append(objects, object)
+		}
+		assert.Empty(t, objects)
+	})
+}
@@ -0,0 +1,55 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestMySQLIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	mySQL, cleanupFn, err := integrationtesting.RunMySQLDockerContainer()
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = mySQL.DB().ExecContext(ctx, "CREATE TABLE customers (id INT PRIMARY KEY, name VARCHAR(64) NOT NULL)")
+	require.NoError(t, err)
+	_, err = mySQL.DB().ExecContext(ctx, `CREATE TABLE orders (
+  id INT PRIMARY KEY,
+  customer_id INT NOT NULL,
+  FOREIGN KEY (customer_id) REFERENCES customers (id)
+)`)
+	require.NoError(t, err)
+
+	countOrders := func(t *testing.T) int {
+		var count int
+		require.NoError(t, mySQL.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM orders").Scan(&count))
+		return count
+	}
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer mySQL.MustTruncateData()
+
+		_, err := mySQL.DB().ExecContext(ctx, "INSERT INTO customers (id, name) VALUES (1, 'Alice')")
+		require.NoError(t, err)
+		_, err = mySQL.DB().ExecContext(ctx, "INSERT INTO orders (id, customer_id) VALUES (1, 1)")
+		require.NoError(t, err)
+		assert.Equal(t, 1, countOrders(t))
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer mySQL.MustTruncateData()
+
+		// the data of the previous test is truncated, despite the foreign key:
+		assert.Equal(t, 0, countOrders(t))
+	})
+}
@@ -0,0 +1,49 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestNATSIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	natsInstance, cleanupFn, err := integrationtesting.RunNATSDockerContainer()
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	natsInstance.MustCreateStreams(integrationtesting.NATSStream{
+		Config: jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}},
+	})
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer natsInstance.MustPurgeStreams()
+		subscriber := natsInstance.Subscribe(t, "orders.>")
+
+		_, err := natsInstance.JetStream().Publish(context.Background(), "orders.created", []byte(`{"id":1}`))
+		require.NoError(t, err)
+
+		subscriber.AssertEventuallyMessageOnSubject(t, "orders.created", 5*time.Second)
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer natsInstance.MustPurgeStreams()
+
+		// messages stored by the stream in the previous test are purged:
+		stream, err := natsInstance.JetStream().Stream(context.Background(), "ORDERS")
+		require.NoError(t, err)
+		info, err := stream.Info(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, info.State.Msgs)
+	})
+}
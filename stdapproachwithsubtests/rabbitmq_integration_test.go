@@ -0,0 +1,51 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestRabbitMQIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	rabbitMQ, cleanupFn, err := integrationtesting.RunRabbitMQDockerContainer(
+		integrationtesting.WithRabbitMQDefinitions("testdata/rabbitmq_definitions.json"),
+	)
+	defer cleanupFn()
+	require.NoError(t, err)
+
+	publish := func(t *testing.T, routingKey, body string) {
+		channel, err := rabbitMQ.Connection().Channel()
+		require.NoError(t, err)
+		defer channel.Close()
+
+		err = channel.PublishWithContext(context.Background(), "orders", routingKey, false, false,
+			amqp.Publishing{ContentType: "application/json", Body: []byte(body)})
+		require.NoError(t, err)
+	}
+
+	t.Run("TestExample1", func(t *testing.T) {
+		defer rabbitMQ.MustPurgeQueues()
+		consumer := rabbitMQ.Consume(t, "orders.created")
+
+		publish(t, "order.created", `{"id":1}`)
+
+		consumer.AssertEventuallyMessageWithRoutingKey(t, "order.created", 5*time.Second)
+	})
+
+	t.Run("TestExample2", func(t *testing.T) {
+		defer rabbitMQ.MustPurgeQueues()
+
+		// the message is left in the queue, which is purged after the test:
+		publish(t, "order.created", `{"id":2}`)
+	})
+}
@@ -0,0 +1,37 @@
+package stdapproachwithsubtests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skovtunenko/testcontainer-examples/integrationtesting"
+	"github.com/skovtunenko/testcontainer-examples/postgresintegration"
+)
+
+func TestRedisIntegrationTest(t *testing.T) {
+	if postgresintegration.IsSkipIntegrationTest(t) {
+		return
+	}
+
+	redis, cleanupFn, err := integrationtesting.RunRedisDockerContainer()
+	t.Cleanup(cleanupFn) // runs after the parallel subtests, unlike defer
+	require.NoError(t, err)
+
+	// every parallel subtest gets its own logical database, so the same keys don't collide:
+	for _, name := range []string{"TestExample1", "TestExample2"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			client := redis.AllocateDB(t)
+
+			require.NoError(t, client.Set(ctx, "owner", name, 0).Err())
+			owner, err := client.Get(ctx, "owner").Result()
+			require.NoError(t, err)
+			assert.Equal(t, name, owner)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE events
+(
+    event_time DateTime,
+    user_id    UInt64,
+    event_type LowCardinality(String)
+)
+ENGINE = MergeTree
+ORDER BY (event_type, event_time);
@@ -0,0 +1,12 @@
+CREATE TABLE events_by_type
+(
+    event_type LowCardinality(String),
+    events     UInt64
+)
+ENGINE = SummingMergeTree
+ORDER BY event_type;
+
+CREATE MATERIALIZED VIEW events_by_type_mv TO events_by_type AS
+SELECT event_type, count() AS events
+FROM events
+GROUP BY event_type;